@@ -0,0 +1,205 @@
+package database
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+type batchConfig struct {
+	checkpoint Key
+	workers    int
+}
+
+// BatchOption configures the iteration of ForEachBatch.
+type BatchOption func(cnf *batchConfig)
+
+// WithCheckpoint resumes the iteration after the row with the key. Store the key
+// of the last model of each processed batch with KeyOf to continue later from
+// the same point if the process is interrupted.
+func WithCheckpoint(key Key) BatchOption {
+	return func(cnf *batchConfig) {
+		cnf.checkpoint = key
+	}
+}
+
+// WithWorkers runs the callback of multiple batches in parallel with the number
+// of goroutines. Batches are still read one after the other, but they can finish
+// in any order; take it into account when storing checkpoints.
+func WithWorkers(workers int) BatchOption {
+	return func(cnf *batchConfig) {
+		cnf.workers = workers
+	}
+}
+
+// ForEachBatch walks the collection in primary key order reading batches of
+// models with the size and calling fn with each one of them. Instead of keeping
+// a single query open for the whole table like Iterator, each batch is read with
+// a new short query that continues after the primary key of the last model of
+// the previous batch. This allows to process huge tables without blocking a
+// connection for a long time.
+//
+// Filters of the collection are applied to every batch, but it should not have
+// orders, limits or offsets. The first error returned by fn stops the iteration
+// and it is returned.
+func (c *Collection) ForEachBatch(size int64, fn func(batch []Model) error, opts ...BatchOption) error {
+	return forEachBatch[Model](c, size, fn, opts)
+}
+
+func forEachBatch[T Model](c *Collection, size int64, fn func(batch []T) error, opts []BatchOption) error {
+	if size <= 0 {
+		panic("ForEachBatch requires a positive batch size")
+	}
+	if c.err != nil {
+		return c.err
+	}
+	if len(c.orders) > 0 || c.limit > 0 || c.offset > 0 {
+		return fmt.Errorf("database: ForEachBatch cannot be used with orders, limits or offsets")
+	}
+	if len(c.meta.primaryKeys()) == 0 {
+		return fmt.Errorf("database: ForEachBatch requires a model with primary keys")
+	}
+
+	cnf := &batchConfig{workers: 1}
+	for _, opt := range opts {
+		opt(cnf)
+	}
+
+	var last Key
+	if cnf.checkpoint != nil {
+		var err error
+		last, err = c.meta.normalizeKey(cnf.checkpoint)
+		if err != nil {
+			return err
+		}
+	}
+
+	if cnf.workers <= 1 {
+		for {
+			batch, err := readBatch[T](c, last, size)
+			if err != nil {
+				return err
+			}
+			if len(batch) == 0 {
+				return nil
+			}
+
+			// Read the key before fn receives the models, it could change them.
+			next := KeyOf(batch[len(batch)-1])
+			if err := fn(batch); err != nil {
+				return err
+			}
+
+			if int64(len(batch)) < size {
+				return nil
+			}
+			last = next
+		}
+	}
+
+	var wg sync.WaitGroup
+	var once sync.Once
+	var failure error
+	done := make(chan struct{})
+	fail := func(err error) {
+		once.Do(func() {
+			failure = err
+			close(done)
+		})
+	}
+
+	batches := make(chan []T)
+	for i := 0; i < cnf.workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for batch := range batches {
+				if err := fn(batch); err != nil {
+					fail(err)
+					return
+				}
+			}
+		}()
+	}
+
+read:
+	for {
+		batch, err := readBatch[T](c, last, size)
+		if err != nil {
+			fail(err)
+			break
+		}
+		if len(batch) == 0 {
+			break
+		}
+
+		// The workers own the batch once it is sent, read the key before.
+		next := KeyOf(batch[len(batch)-1])
+		select {
+		case batches <- batch:
+		case <-done:
+			break read
+		}
+
+		if int64(len(batch)) < size {
+			break
+		}
+		last = next
+	}
+	close(batches)
+	wg.Wait()
+
+	return failure
+}
+
+// readBatch reads the models of the collection that go after the key in primary
+// key order. A nil key reads the first batch.
+func readBatch[T Model](c *Collection, last Key, size int64) ([]T, error) {
+	c = c.Clone()
+	if last != nil {
+		c.FilterCond(c.keysetCondition(last))
+	}
+	for _, pk := range c.meta.primaryKeys() {
+		c.orders = append(c.orders, &sqlSorter{sql: fmt.Sprintf("%s ASC", c.column(pk.name))})
+	}
+	c.limit = size
+
+	dest := reflect.New(reflect.SliceOf(reflect.TypeOf(c.model)))
+	if err := c.GetAll(dest.Interface()); err != nil {
+		return nil, err
+	}
+
+	batch := make([]T, dest.Elem().Len())
+	for i := range batch {
+		batch[i] = dest.Elem().Index(i).Interface().(T)
+	}
+
+	return batch, nil
+}
+
+// keysetCondition filters the rows that go after the key in primary key order.
+// Composite primary keys are compared as tuples:
+//
+//	(a, b) > (?, ?)
+func (c *Collection) keysetCondition(key Key) Condition {
+	pks := c.meta.primaryKeys()
+
+	cols := make([]string, len(pks))
+	placeholders := make([]string, len(pks))
+	for i, pk := range pks {
+		cols[i] = c.column(pk.name)
+		placeholders[i] = "?"
+	}
+	if len(pks) == 1 {
+		return &sqlCondition{
+			sql:    fmt.Sprintf("%s > ?", cols[0]),
+			values: key,
+		}
+	}
+
+	return &sqlCondition{
+		sql:    fmt.Sprintf("(%s) > (%s)", strings.Join(cols, ", "), strings.Join(placeholders, ", ")),
+		values: key,
+	}
+}
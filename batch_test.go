@@ -0,0 +1,125 @@
+package database
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeysetCondition(t *testing.T) {
+	c := newCollection(new(Database), new(testingCompositeModel))
+	cond := c.keysetCondition(Key{int64(3), "foo"})
+	require.Equal(t, "(`id`, `code`) > (?, ?)", cond.SQL())
+	require.Equal(t, []interface{}{int64(3), "foo"}, cond.Values())
+
+	c = newCollection(new(Database), new(testingAutoModel)).Alias("a").Join(newCollection(new(Database), new(testingModel)), "a.name = testing.name")
+	cond = c.keysetCondition(Key{int64(3)})
+	require.Equal(t, "a.`id` > ?", cond.SQL())
+}
+
+func TestForEachBatchWithOrders(t *testing.T) {
+	c := newCollection(new(Database), new(testingAutoModel)).Order("name")
+	err := c.ForEachBatch(10, func(batch []Model) error { return nil })
+	require.EqualError(t, err, "database: ForEachBatch cannot be used with orders, limits or offsets")
+}
+
+func insertBatchFixtures(t *testing.T) {
+	for i := 0; i < 7; i++ {
+		require.Nil(t, testingsAuto.Put(&testingAutoModel{Name: fmt.Sprintf("name %d", i)}))
+	}
+}
+
+func TestForEachBatch(t *testing.T) {
+	initDatabase(t)
+	defer closeDatabase()
+	insertBatchFixtures(t)
+
+	var sizes []int
+	var ids []int64
+	tc := NewTypedCollection[*testingAutoModel](testDB)
+	require.Nil(t, tc.ForEachBatch(3, func(batch []*testingAutoModel) error {
+		sizes = append(sizes, len(batch))
+		for _, model := range batch {
+			ids = append(ids, model.ID)
+		}
+		return nil
+	}))
+	require.Equal(t, []int{3, 3, 1}, sizes)
+	require.Equal(t, []int64{1, 2, 3, 4, 5, 6, 7}, ids)
+}
+
+func TestForEachBatchCheckpoint(t *testing.T) {
+	initDatabase(t)
+	defer closeDatabase()
+	insertBatchFixtures(t)
+
+	var ids []int64
+	err := testingsAuto.ForEachBatch(2, func(batch []Model) error {
+		for _, model := range batch {
+			ids = append(ids, model.(*testingAutoModel).ID)
+		}
+		return nil
+	}, WithCheckpoint(NewKey(4)))
+	require.Nil(t, err)
+	require.Equal(t, []int64{5, 6, 7}, ids)
+}
+
+func TestForEachBatchWorkers(t *testing.T) {
+	initDatabase(t)
+	defer closeDatabase()
+	insertBatchFixtures(t)
+
+	var mu sync.Mutex
+	var ids []int64
+	err := testingsAuto.Clone().Filter("id >", 1).ForEachBatch(2, func(batch []Model) error {
+		mu.Lock()
+		defer mu.Unlock()
+		for _, model := range batch {
+			ids = append(ids, model.(*testingAutoModel).ID)
+		}
+		return nil
+	}, WithWorkers(3))
+	require.Nil(t, err)
+
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	require.Equal(t, []int64{2, 3, 4, 5, 6, 7}, ids)
+}
+
+func TestForEachBatchWorkersChangeModels(t *testing.T) {
+	initDatabase(t)
+	defer closeDatabase()
+	insertBatchFixtures(t)
+
+	var mu sync.Mutex
+	var ids []int64
+	err := testingsAuto.ForEachBatch(2, func(batch []Model) error {
+		mu.Lock()
+		defer mu.Unlock()
+		for _, model := range batch {
+			ids = append(ids, model.(*testingAutoModel).ID)
+			model.(*testingAutoModel).ID = 0
+		}
+		return nil
+	}, WithWorkers(2))
+	require.Nil(t, err)
+
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	require.Equal(t, []int64{1, 2, 3, 4, 5, 6, 7}, ids)
+}
+
+func TestForEachBatchError(t *testing.T) {
+	initDatabase(t)
+	defer closeDatabase()
+	insertBatchFixtures(t)
+
+	var calls int
+	err := testingsAuto.ForEachBatch(2, func(batch []Model) error {
+		calls++
+		return fmt.Errorf("foo error")
+	})
+	require.EqualError(t, err, "foo error")
+	require.Equal(t, 1, calls)
+}
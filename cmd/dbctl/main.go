@@ -0,0 +1,11 @@
+// Command dbctl runs migrations and inspects the schema of a MySQL database.
+// Run "dbctl -help" to see the list of commands.
+package main
+
+import (
+	"github.com/altipla-consulting/database/dbctl"
+)
+
+func main() {
+	dbctl.Main()
+}
@@ -0,0 +1,470 @@
+// Command dbgen generates typed mappers for the models of a package to read and
+// write them to the database without reflection.
+//
+// Add the following line to any file of the package that contains the models:
+//
+//	//go:generate go run github.com/altipla-consulting/database/cmd/dbgen
+//
+// Every struct with a TableName method will receive the methods of the
+// database.Mapper interface and a list of constants with the names of its
+// columns. The constants are plain strings: using them in Filter and Order calls
+// instead of literals only makes the compiler report the columns that were
+// renamed or removed after generating the file again. Collections use the
+// generated mappers automatically.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"log"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+const libraryPath = "github.com/altipla-consulting/database"
+
+var (
+	flagOutput = flag.String("output", "models_dbgen.go", "Name of the generated file inside the package directory.")
+	flagDir    = flag.String("dir", ".", "Directory of the package that contains the models.")
+)
+
+func main() {
+	flag.Parse()
+
+	if err := run(*flagDir, *flagOutput); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func run(dir, output string) error {
+	content, err := generate(dir, output)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(filepath.Join(dir, output), content, 0644)
+}
+
+// column is a single column of a model.
+type column struct {
+	// Name of the column in the database.
+	name string
+
+	// Go expression to access the field from the receiver.
+	expr string
+
+	// Name of the field, including the parents of named inline structs.
+	field string
+}
+
+// model is a struct of the package that has a TableName method.
+type model struct {
+	name    string
+	columns []column
+}
+
+type pkg struct {
+	dir     string
+	fset    *token.FileSet
+	name    string
+	structs map[string]*ast.StructType
+	methods map[string]map[string]bool
+
+	// Name the library is imported with in each file.
+	imports map[*ast.File]string
+
+	// File where each struct is declared and import paths of each file by the
+	// name they are imported with.
+	files       map[*ast.StructType]*ast.File
+	importPaths map[*ast.File]map[string]string
+
+	// Packages imported to check the types of other packages.
+	importer types.ImporterFrom
+}
+
+func generate(dir, output string) ([]byte, error) {
+	p, err := parsePackage(dir, output)
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for name := range p.structs {
+		if p.methods[name]["TableName"] {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var models []*model
+	for _, name := range names {
+		m := &model{name: name}
+		if err := p.walk(m, p.structs[name], "model", "", ""); err != nil {
+			return nil, fmt.Errorf("model %s: %w", name, err)
+		}
+		models = append(models, m)
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintln(&buf, "// Code generated by dbgen. DO NOT EDIT.")
+	fmt.Fprintln(&buf)
+	fmt.Fprintf(&buf, "package %s\n", p.name)
+	for _, m := range models {
+		fmt.Fprintln(&buf)
+		fmt.Fprintf(&buf, "// Columns of the %s model.\n", m.name)
+		fmt.Fprintln(&buf, "const (")
+		for _, col := range m.columns {
+			fmt.Fprintf(&buf, "%sCol%s = %s\n", m.name, strings.Replace(col.field, ".", "", -1), strconv.Quote(col.name))
+		}
+		fmt.Fprintln(&buf, ")")
+
+		fmt.Fprintln(&buf)
+		fmt.Fprintln(&buf, "// DBColumns returns the escaped names of the columns of the model.")
+		fmt.Fprintf(&buf, "func (model *%s) DBColumns() []string {\n", m.name)
+		fmt.Fprintln(&buf, "return []string{")
+		for _, col := range m.columns {
+			fmt.Fprintf(&buf, "%s,\n", strconv.Quote("`"+col.name+"`"))
+		}
+		fmt.Fprintln(&buf, "}")
+		fmt.Fprintln(&buf, "}")
+
+		fmt.Fprintln(&buf)
+		fmt.Fprintln(&buf, "// DBValues returns the value of each column of the model.")
+		fmt.Fprintf(&buf, "func (model *%s) DBValues() []interface{} {\n", m.name)
+		fmt.Fprintln(&buf, "return []interface{}{")
+		for _, col := range m.columns {
+			fmt.Fprintf(&buf, "%s,\n", col.expr)
+		}
+		fmt.Fprintln(&buf, "}")
+		fmt.Fprintln(&buf, "}")
+
+		fmt.Fprintln(&buf)
+		fmt.Fprintln(&buf, "// DBPointers returns a pointer to the field of each column of the model.")
+		fmt.Fprintf(&buf, "func (model *%s) DBPointers() []interface{} {\n", m.name)
+		fmt.Fprintln(&buf, "return []interface{}{")
+		for _, col := range m.columns {
+			fmt.Fprintf(&buf, "&%s,\n", col.expr)
+		}
+		fmt.Fprintln(&buf, "}")
+		fmt.Fprintln(&buf, "}")
+	}
+
+	return format.Source(buf.Bytes())
+}
+
+func parsePackage(dir, output string) (*pkg, error) {
+	matches, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return nil, err
+	}
+
+	fset := token.NewFileSet()
+	p := &pkg{
+		dir:         dir,
+		fset:        fset,
+		structs:     map[string]*ast.StructType{},
+		methods:     map[string]map[string]bool{},
+		imports:     map[*ast.File]string{},
+		files:       map[*ast.StructType]*ast.File{},
+		importPaths: map[*ast.File]map[string]string{},
+	}
+	for _, match := range matches {
+		if strings.HasSuffix(match, "_test.go") || filepath.Base(match) == output {
+			continue
+		}
+
+		f, err := parser.ParseFile(fset, match, nil, parser.SkipObjectResolution)
+		if err != nil {
+			return nil, err
+		}
+		if p.name == "" {
+			p.name = f.Name.Name
+		} else if p.name != f.Name.Name {
+			return nil, fmt.Errorf("multiple packages in %s: %s and %s", dir, p.name, f.Name.Name)
+		}
+
+		p.importPaths[f] = map[string]string{}
+		for _, imp := range f.Imports {
+			path, _ := strconv.Unquote(imp.Path.Value)
+			name := filepath.Base(path)
+			if imp.Name != nil {
+				name = imp.Name.Name
+			}
+			p.importPaths[f][name] = path
+
+			if path != libraryPath {
+				continue
+			}
+			p.imports[f] = "database"
+			if imp.Name != nil {
+				p.imports[f] = imp.Name.Name
+			}
+		}
+
+		for _, decl := range f.Decls {
+			switch decl := decl.(type) {
+			case *ast.GenDecl:
+				for _, spec := range decl.Specs {
+					ts, ok := spec.(*ast.TypeSpec)
+					if !ok {
+						continue
+					}
+					if st, ok := ts.Type.(*ast.StructType); ok {
+						p.structs[ts.Name.Name] = st
+						p.files[st] = f
+					}
+				}
+
+			case *ast.FuncDecl:
+				if decl.Recv == nil || len(decl.Recv.List) != 1 {
+					continue
+				}
+				recv := decl.Recv.List[0].Type
+				if star, ok := recv.(*ast.StarExpr); ok {
+					recv = star.X
+				}
+				ident, ok := recv.(*ast.Ident)
+				if !ok {
+					continue
+				}
+				if p.methods[ident.Name] == nil {
+					p.methods[ident.Name] = map[string]bool{}
+				}
+				p.methods[ident.Name][decl.Name.Name] = true
+			}
+		}
+	}
+	if p.name == "" {
+		return nil, fmt.Errorf("no Go files found in %s", dir)
+	}
+
+	return p, nil
+}
+
+// walk appends the columns of a struct to the model, following the same rules
+// the library applies when reading the struct through reflection.
+func (p *pkg) walk(m *model, st *ast.StructType, expr, prefix, fieldPrefix string) error {
+	for _, f := range st.Fields.List {
+		typeName, isTracking := p.typeName(f.Type)
+
+		names := []string{typeName}
+		anonymous := len(f.Names) == 0
+		if !anonymous {
+			names = nil
+			for _, name := range f.Names {
+				names = append(names, name.Name)
+			}
+		}
+
+		var tag string
+		if f.Tag != nil {
+			unquoted, err := strconv.Unquote(f.Tag.Value)
+			if err != nil {
+				return err
+			}
+			tag = reflect.StructTag(unquoted).Get("db")
+		}
+
+		for _, name := range names {
+			if !startsWithUppercase(name) {
+				continue
+			}
+
+			fieldExpr := expr + "." + name
+
+			if anonymous && isTracking {
+				m.columns = append(m.columns, column{
+					name:  "revision",
+					expr:  fieldExpr + ".Revision",
+					field: "Revision",
+				})
+				continue
+			}
+
+			opts := parseTag(tag)
+			if opts.name == "-" {
+				continue
+			}
+
+			embeddable := p.isEmbeddable(f.Type)
+			if anonymous && opts.name == "" && embeddable {
+				opts.inline = true
+			}
+			if anonymous && opts.name == "" && !opts.inline {
+				if sel, ok := f.Type.(*ast.SelectorExpr); ok {
+					// The library flattens embedded structs of other packages too, but we
+					// cannot read their fields from here. Scalar types are a single column.
+					embeddable, err := p.isForeignEmbeddable(st, sel)
+					if err != nil {
+						return fmt.Errorf("cannot embed field %s: %w", name, err)
+					}
+					if embeddable {
+						return fmt.Errorf("cannot embed field %s: it is a struct of another package, tag it with a column name", name)
+					}
+				}
+			}
+			if opts.inline {
+				if !embeddable {
+					return fmt.Errorf("cannot inline field %s: it is not a struct of the same package", name)
+				}
+
+				field := fieldPrefix
+				if !anonymous {
+					field += name + "."
+				}
+				if err := p.walk(m, p.structs[typeName], fieldExpr, prefix+opts.prefix, field); err != nil {
+					return err
+				}
+
+				continue
+			}
+			if opts.prefix != "" {
+				return fmt.Errorf("prefix can only be used with inline fields: %s", name)
+			}
+
+			col := name
+			if opts.name != "" {
+				col = opts.name
+			}
+			m.columns = append(m.columns, column{
+				name:  prefix + col,
+				expr:  fieldExpr,
+				field: fieldPrefix + name,
+			})
+		}
+	}
+
+	return nil
+}
+
+// typeName returns the name of the type of a field and whether it is the
+// ModelTracking type of the library.
+func (p *pkg) typeName(expr ast.Expr) (string, bool) {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		return t.Name, p.name == "database" && t.Name == "ModelTracking"
+
+	case *ast.SelectorExpr:
+		x, ok := t.X.(*ast.Ident)
+		if !ok {
+			return t.Sel.Name, false
+		}
+		for _, name := range p.imports {
+			if name == x.Name && t.Sel.Name == "ModelTracking" {
+				return t.Sel.Name, true
+			}
+		}
+		return t.Sel.Name, false
+
+	case *ast.StarExpr:
+		name, _ := p.typeName(t.X)
+		return name, false
+	}
+
+	return "", false
+}
+
+// isEmbeddable returns true if the type is a struct of the same package that
+// is not scanned directly as a single column.
+func (p *pkg) isEmbeddable(expr ast.Expr) bool {
+	ident, ok := expr.(*ast.Ident)
+	if !ok {
+		return false
+	}
+	if _, ok := p.structs[ident.Name]; !ok {
+		return false
+	}
+
+	return !p.methods[ident.Name]["Scan"] && !p.methods[ident.Name]["Value"]
+}
+
+// isForeignEmbeddable returns true if the type of another package is a struct that
+// the library flattens when embedded, following the same rules as isEmbeddable.
+// The package is type checked from its sources.
+func (p *pkg) isForeignEmbeddable(st *ast.StructType, sel *ast.SelectorExpr) (bool, error) {
+	x, ok := sel.X.(*ast.Ident)
+	if !ok {
+		return false, fmt.Errorf("unknown type %s", sel.Sel.Name)
+	}
+	path, ok := p.importPaths[p.files[st]][x.Name]
+	if !ok {
+		return false, fmt.Errorf("unknown package %s", x.Name)
+	}
+	if path == "time" && sel.Sel.Name == "Time" {
+		return false, nil
+	}
+
+	if p.importer == nil {
+		p.importer = importer.ForCompiler(p.fset, "source", nil).(types.ImporterFrom)
+	}
+	imported, err := p.importer.ImportFrom(path, p.dir, 0)
+	if err != nil {
+		return false, fmt.Errorf("cannot load package %s: %w", path, err)
+	}
+	obj, ok := imported.Scope().Lookup(sel.Sel.Name).(*types.TypeName)
+	if !ok {
+		return false, fmt.Errorf("unknown type %s.%s", x.Name, sel.Sel.Name)
+	}
+	if _, ok := obj.Type().Underlying().(*types.Struct); !ok {
+		return false, nil
+	}
+
+	ptr := types.NewPointer(obj.Type())
+	for _, method := range []string{"Scan", "Value"} {
+		if m, _, _ := types.LookupFieldOrMethod(ptr, true, obj.Pkg(), method); m != nil {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+type structTag struct {
+	name   string
+	inline bool
+	prefix string
+}
+
+// parseTag reads the options of the tag that affect the list of columns. The
+// rest of them are validated by the library when the collection is created.
+func parseTag(tag string) structTag {
+	if tag == "" {
+		return structTag{}
+	}
+
+	parts := strings.Split(tag, ",")
+	st := structTag{
+		name: parts[0],
+	}
+	for _, part := range parts[1:] {
+		switch {
+		case part == "inline":
+			st.inline = true
+
+		case strings.HasPrefix(part, "prefix="):
+			st.prefix = strings.TrimPrefix(part, "prefix=")
+		}
+	}
+
+	return st
+}
+
+func startsWithUppercase(s string) bool {
+	for _, r := range s {
+		return unicode.IsUpper(r)
+	}
+
+	return false
+}
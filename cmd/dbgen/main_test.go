@@ -0,0 +1,82 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerate(t *testing.T) {
+	content, err := generate("testdata", "models_dbgen.go")
+	require.Nil(t, err)
+
+	expected, err := os.ReadFile(filepath.Join("testdata", "models_dbgen.go.golden"))
+	require.Nil(t, err)
+
+	require.Equal(t, string(expected), string(content))
+}
+
+func TestGenerateInlineOutsidePackage(t *testing.T) {
+	dir := t.TempDir()
+	require.Nil(t, os.WriteFile(filepath.Join(dir, "models.go"), []byte(`package models
+
+import "time"
+
+type Hotel struct {
+	Created time.Time `+"`"+`db:",inline"`+"`"+`
+}
+
+func (hotel *Hotel) TableName() string {
+	return "hotels"
+}
+`), 0644))
+
+	_, err := generate(dir, "models_dbgen.go")
+	require.EqualError(t, err, "model Hotel: cannot inline field Created: it is not a struct of the same package")
+}
+
+func TestGenerateEmbeddedOutsidePackage(t *testing.T) {
+	dir := t.TempDir()
+	require.Nil(t, os.WriteFile(filepath.Join(dir, "models.go"), []byte(`package models
+
+import "bytes"
+
+type Hotel struct {
+	bytes.Buffer
+}
+
+func (hotel *Hotel) TableName() string {
+	return "hotels"
+}
+`), 0644))
+
+	_, err := generate(dir, "models_dbgen.go")
+	require.EqualError(t, err, "model Hotel: cannot embed field Buffer: it is a struct of another package, tag it with a column name")
+}
+
+func TestGenerateEmbeddedScalarOutsidePackage(t *testing.T) {
+	dir := t.TempDir()
+	require.Nil(t, os.WriteFile(filepath.Join(dir, "models.go"), []byte(`package models
+
+import (
+	"database/sql"
+	"time"
+)
+
+type Hotel struct {
+	time.Time
+	sql.NullString
+}
+
+func (hotel *Hotel) TableName() string {
+	return "hotels"
+}
+`), 0644))
+
+	content, err := generate(dir, "models_dbgen.go")
+	require.Nil(t, err)
+	require.Contains(t, string(content), "HotelColTime       = \"Time\"")
+	require.Contains(t, string(content), "HotelColNullString = \"NullString\"")
+}
@@ -0,0 +1,45 @@
+package models
+
+import (
+	"time"
+
+	"github.com/altipla-consulting/database"
+)
+
+//go:generate go run github.com/altipla-consulting/database/cmd/dbgen
+
+type Address struct {
+	Street string `db:"street"`
+	City   string `db:"city"`
+}
+
+type Audit struct {
+	UpdatedBy string `db:"updated_by"`
+}
+
+type Hotel struct {
+	database.ModelTracking
+	Audit
+
+	ID         int64     `db:"id,pk"`
+	Name       string    `db:"name"`
+	Billing    Address   `db:",inline,prefix=billing_"`
+	Created    time.Time `db:"created"`
+	Ignored    string    `db:"-"`
+	unexported string
+}
+
+func (hotel *Hotel) TableName() string {
+	return "hotels"
+}
+
+type Room struct {
+	database.ModelTracking
+
+	HotelID int64  `db:"hotel_id,pk"`
+	Code    string `db:"code,pk"`
+}
+
+func (room *Room) TableName() string {
+	return "rooms"
+}
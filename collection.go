@@ -1,11 +1,14 @@
 package database
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"log"
 	"reflect"
 	"strings"
+
+	"github.com/altipla-consulting/database/internal/fields"
 )
 
 // Collection represents a table. You can apply further filters and operations
@@ -13,26 +16,38 @@ import (
 // or use it to store new items (Put).
 type Collection struct {
 	sess          *sql.DB
+	dialect       Dialect
 	debug         bool
 	conditions    []Condition
-	orders        []string
+	orders        []Sorter
 	offset, limit int64
 	model         Model
+	meta          *modelMetadata
 	props         []*Property
 	alias         string
+	preloads      []string
+	joins         []*collectionJoin
+
+	// First error building the collection, returned when the query runs.
+	err error
 }
 
 func newCollection(db *Database, model Model) *Collection {
-	props, err := extractModelProps(model)
+	meta, err := modelMetadataOf(reflect.TypeOf(model))
 	if err != nil {
 		panic(err)
 	}
+	if err := meta.checkMapper(model); err != nil {
+		panic(err)
+	}
 
 	c := &Collection{
-		sess:  db.sess,
-		debug: db.debug,
-		model: model,
-		props: props,
+		sess:    db.sess,
+		dialect: dialectOf(db),
+		debug:   db.debug,
+		model:   model,
+		meta:    meta,
+		props:   meta.props(reflect.ValueOf(model).Elem()),
 	}
 
 	return c
@@ -43,20 +58,45 @@ func newCollection(db *Database, model Model) *Collection {
 func (c *Collection) Clone() *Collection {
 	return &Collection{
 		sess:       c.sess,
+		dialect:    c.dialect,
 		conditions: c.conditions,
 		orders:     c.orders,
 		offset:     c.offset,
 		limit:      c.limit,
 		model:      c.model,
+		meta:       c.meta,
 		props:      c.props,
 		alias:      c.alias,
 		debug:      c.debug,
+		preloads:   c.preloads,
+		joins:      c.joins,
+		err:        c.err,
 	}
 }
 
+// Err returns the first error building the collection, like an unknown column in
+// Filter or Order. Methods that run queries return it too without running them, so
+// it only needs to be checked to report the error before, for example to answer
+// with a bad request when the columns come from the parameters of an API.
+func (c *Collection) Err() error {
+	return c.err
+}
+
+// fail records an error building the collection if there was none before.
+func (c *Collection) fail(err error) *Collection {
+	if c.err == nil {
+		c.err = err
+	}
+	return c
+}
+
 // Alias changes the name of the table in the SQL query. It is useful in combination
 // with FilterExists() to have a stable name for the tables that should be filtered.
 func (c *Collection) Alias(alias string) *Collection {
+	if !aliasRe.MatchString(alias) {
+		panic(fmt.Sprintf("invalid alias: %s", alias))
+	}
+
 	c.alias = alias
 	return c
 }
@@ -64,17 +104,23 @@ func (c *Collection) Alias(alias string) *Collection {
 // Get retrieves the model matching the collection filters and the model primary key.
 // If no model is found ErrNoSuchEntity will be returned and the model won't be touched.
 func (c *Collection) Get(instance Model) error {
-	modelProps := updatedProps(c.props, instance)
+	if c.err != nil {
+		return c.err
+	}
+
+	modelProps := c.meta.modelProps(instance)
 	b := &sqlBuilder{
+		dialect:    c.dialect,
 		table:      c.model.TableName(),
 		conditions: c.conditions,
 		alias:      c.alias,
 		props:      modelProps,
+		joins:      c.sqlJoins(),
 	}
 
 	for _, prop := range modelProps {
 		if prop.PrimaryKey {
-			b.conditions = append(b.conditions, Filter(prop.Name, prop.Value))
+			b.conditions = append(b.conditions, Filter(c.column(prop.Name), prop.Value))
 		}
 	}
 
@@ -83,11 +129,8 @@ func (c *Collection) Get(instance Model) error {
 		log.Println("database [Get]:", statement)
 	}
 
-	var pointers []interface{}
-	for _, prop := range modelProps {
-		pointers = append(pointers, prop.Pointer)
-	}
-	if err := c.sess.QueryRow(statement, values...).Scan(pointers...); err != nil {
+	pointers := c.meta.scanPointers(instance)
+	if err := c.queryRow(statement, values...).Scan(pointers...); err != nil {
 		if err == sql.ErrNoRows {
 			return ErrNoSuchEntity
 		}
@@ -95,9 +138,12 @@ func (c *Collection) Get(instance Model) error {
 		return err
 	}
 
-	modelProps = updatedProps(c.props, instance)
+	modelProps = c.meta.modelProps(instance)
+	if err := instance.Tracking().AfterGet(modelProps); err != nil {
+		return err
+	}
 
-	return instance.Tracking().AfterGet(modelProps)
+	return c.preloadOne(instance)
 }
 
 // Put stores a new item of the collection. Any filter or limit of the
@@ -108,6 +154,12 @@ func (c *Collection) Put(instance Model) error {
 	if modelt != instancet {
 		return fmt.Errorf("database: expected instance of %s and got a instance of %s", modelt, instancet)
 	}
+	if c.err != nil {
+		return c.err
+	}
+	if len(c.joins) > 0 {
+		return fmt.Errorf("database: cannot put models in a collection with joins")
+	}
 
 	if h, ok := instance.(OnBeforePutHooker); ok {
 		if err := h.OnBeforePutHook(); err != nil {
@@ -116,12 +168,14 @@ func (c *Collection) Put(instance Model) error {
 	}
 
 	b := &sqlBuilder{
-		table: c.model.TableName(),
+		dialect: c.dialect,
+		table:   c.model.TableName(),
 	}
-	modelProps := updatedProps(c.props, instance)
+	modelProps := c.meta.modelProps(instance)
 
 	var q string
 	var values []interface{}
+	var auto *Property
 	if instance.Tracking().IsInserted() {
 		b.conditions = append(b.conditions, c.conditions...)
 		b.conditions = append(b.conditions, Filter("revision", instance.Tracking().StoredRevision()))
@@ -141,52 +195,57 @@ func (c *Collection) Put(instance Model) error {
 
 		q, values = b.UpdateSQL()
 	} else {
+		auto = autoIncrementProp(modelProps)
 		for _, prop := range modelProps {
 			if prop.OmitEmpty && isZero(prop.Value) {
 				continue
 			}
+			if prop == auto && isZero(prop.Value) {
+				continue
+			}
 
 			b.props = append(b.props, prop)
 		}
 
 		q, values = b.InsertSQL()
 	}
-	if c.debug {
-		log.Println("database [Put]:", q)
+	var returning string
+	if auto != nil {
+		returning = c.dialect.InsertReturning(unquoteColumn(auto.Name))
 	}
-
-	result, err := c.sess.Exec(q, values...)
-	if err != nil {
-		return err
+	if c.debug {
+		log.Println("database [Put]:", q+returning)
 	}
 
-	var pks int
-	for _, prop := range modelProps {
-		if prop.PrimaryKey {
-			pks++
+	if returning != "" {
+		var id int64
+		if err := c.queryRow(q+returning, values...).Scan(&id); err != nil {
+			return err
 		}
-	}
-	if pks == 1 && !instance.Tracking().IsInserted() {
-		id, err := result.LastInsertId()
+		reflect.ValueOf(auto.Pointer).Elem().Set(reflect.ValueOf(id))
+	} else {
+		result, err := c.exec(q, values...)
 		if err != nil {
-			return fmt.Errorf("database: cannot get last inserted id: %s", err)
+			return err
 		}
 
-		for _, prop := range modelProps {
-			if prop.PrimaryKey {
-				if _, ok := prop.Value.(int64); ok {
-					reflect.ValueOf(prop.Pointer).Elem().Set(reflect.ValueOf(id))
-				}
+		if auto != nil {
+			id, err := result.LastInsertId()
+			if err != nil {
+				return fmt.Errorf("database: cannot get last inserted id: %s", err)
+			}
+			if id != 0 || len(c.meta.primaryKeys()) == 1 {
+				reflect.ValueOf(auto.Pointer).Elem().Set(reflect.ValueOf(id))
 			}
 		}
-	}
 
-	rows, err := result.RowsAffected()
-	if err != nil {
-		return fmt.Errorf("database: cannot get rows affected: %s", err)
-	}
-	if rows == 0 {
-		return ErrConcurrentTransaction
+		rows, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("database: cannot get rows affected: %s", err)
+		}
+		if rows == 0 {
+			return ErrConcurrentTransaction
+		}
 	}
 
 	if err := instance.Tracking().AfterPut(modelProps); err != nil {
@@ -203,23 +262,60 @@ func (c *Collection) Put(instance Model) error {
 }
 
 // Filter applies a new simple filter to the collection. See the global Filter
-// function for documentation.
+// function for documentation. Columns are validated with Column, so they can be
+// the name of the Go field too, and columns of other models should be qualified
+// with the alias of a joined collection. Unknown columns are not added and the
+// error is returned when the query runs; see Err.
 func (c *Collection) Filter(sql string, value interface{}) *Collection {
+	sql = strings.TrimSpace(sql)
+	if sql != "" && !strings.Contains(sql, "?") {
+		parts := strings.SplitN(sql, " ", 2)
+		column, err := c.resolveOperand(parts[0])
+		if err != nil {
+			return c.fail(err)
+		}
+		parts[0] = column
+		sql = strings.Join(parts, " ")
+	}
+
 	return c.FilterCond(Filter(sql, value))
 }
 
 // FilterIsNil applies a new NULL filter to the collection. See the global FilterIsNil
-// function for documentation.
+// function for documentation. The column is validated like in Filter.
 func (c *Collection) FilterIsNil(column string) *Collection {
+	column, err := c.Column(column)
+	if err != nil {
+		return c.fail(err)
+	}
+
 	return c.FilterCond(FilterIsNil(column))
 }
 
 // FilterIsNotNil applies a new NOT NULL filter to the collection. See the
-// global FilterIsNotNil function for documentation.
+// global FilterIsNotNil function for documentation. The column is validated
+// like in Filter.
 func (c *Collection) FilterIsNotNil(column string) *Collection {
+	column, err := c.Column(column)
+	if err != nil {
+		return c.fail(err)
+	}
+
 	return c.FilterCond(FilterIsNotNil(column))
 }
 
+// FilterExpr applies a new filter with named parameters to the collection. See the
+// global FilterExpr function for documentation. Errors binding the parameters are
+// returned when the query runs; see Err.
+func (c *Collection) FilterExpr(sql string, params Named) *Collection {
+	sql, values, err := bindNamed(c.dialect, sql, params)
+	if err != nil {
+		return c.fail(err)
+	}
+
+	return c.FilterCond(&sqlCondition{sql, values})
+}
+
 // FilterCond applies a generic condition to the collection. We have some helpers
 // in this library to build conditions; and other libraries (like github.com/altipla-consulting/geo)
 // can implement their own conditions too.
@@ -247,7 +343,8 @@ func (c *Collection) Limit(limit int64) *Collection {
 
 // Order the collection of items. You can pass "column" for ascendent order or "-column"
 // for descendent order. If you want to order by mutliple columns call Order multiple
-// times for each column, the will be joined.
+// times for each column, the will be joined. Columns are validated like in Filter
+// and unknown columns return an error when the query runs.
 func (c *Collection) Order(column string) *Collection {
 	if strings.Contains(column, ",") {
 		panic("call Order multiple times, do not pass multiple columns")
@@ -259,21 +356,26 @@ func (c *Collection) Order(column string) *Collection {
 		panic("do not call Order with `foo DESC`, use plain `-foo` instead")
 	}
 
+	direction := "ASC"
 	if strings.HasPrefix(column, "-") {
-		column = fmt.Sprintf("`%s` DESC", column[1:])
-	} else {
-		column = fmt.Sprintf("`%s` ASC", column)
+		column = column[1:]
+		direction = "DESC"
+	}
+	column, err := c.Column(column)
+	if err != nil {
+		return c.fail(err)
 	}
 
-	c.orders = append(c.orders, column)
+	c.orders = append(c.orders, &sqlSorter{sql: fmt.Sprintf("%s %s", quoteColumn(column), direction)})
 	return c
 }
 
 // OrderSorter sorts the collection of items. We have some helpers
 // in this library to build sorters; and other libraries (like github.com/altipla-consulting/geo)
-// can implement their own sorters too.
+// can implement their own sorters too. Sorters that implement ValuesSorter
+// can use placeholders instead of interpolating runtime values in the SQL.
 func (c *Collection) OrderSorter(sorter Sorter) *Collection {
-	c.orders = append(c.orders, sorter.SQL())
+	c.orders = append(c.orders, sorter)
 	return c
 }
 
@@ -282,14 +384,22 @@ func (c *Collection) OrderSorter(sorter Sorter) *Collection {
 // PK exists when the filters do not match. Limits won't be applied but the offset
 // of the collection will.
 func (c *Collection) Delete(instance Model) error {
+	if c.err != nil {
+		return c.err
+	}
+	if len(c.joins) > 0 {
+		return fmt.Errorf("database: cannot delete models in a collection with joins")
+	}
+
 	b := &sqlBuilder{
+		dialect:    c.dialect,
 		table:      c.model.TableName(),
 		conditions: c.conditions,
 		limit:      1,
 		offset:     c.offset,
 		alias:      c.alias,
 	}
-	modelProps := updatedProps(c.props, instance)
+	modelProps := c.meta.modelProps(instance)
 
 	for _, prop := range modelProps {
 		if prop.PrimaryKey {
@@ -302,7 +412,7 @@ func (c *Collection) Delete(instance Model) error {
 		log.Println("database [Delete]:", statement)
 	}
 
-	if _, err := c.sess.Exec(statement, values...); err != nil {
+	if _, err := c.exec(statement, values...); err != nil {
 		return err
 	}
 
@@ -312,7 +422,29 @@ func (c *Collection) Delete(instance Model) error {
 // Iterator returns a new iterator that can be used to extract models one by one in a loop.
 // You should close the Iterator after you are done with it.
 func (c *Collection) Iterator() (*Iterator, error) {
+	return c.iterator(context.Background())
+}
+
+func (c *Collection) iterator(ctx context.Context) (*Iterator, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+	if len(c.preloads) > 0 {
+		return nil, fmt.Errorf("database: iterators cannot preload relations, use GetAll or ForEachBatch instead")
+	}
+
+	return c.openIterator(ctx)
+}
+
+// openIterator runs the query of the collection without checking the preloaded
+// relations, for GetAll that loads them itself after reading the rows.
+func (c *Collection) openIterator(ctx context.Context) (*Iterator, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+
 	b := &sqlBuilder{
+		dialect:    c.dialect,
 		table:      c.model.TableName(),
 		conditions: c.conditions,
 		props:      c.props,
@@ -320,6 +452,7 @@ func (c *Collection) Iterator() (*Iterator, error) {
 		offset:     c.offset,
 		orders:     c.orders,
 		alias:      c.alias,
+		joins:      c.sqlJoins(),
 	}
 
 	sql, values := b.SelectSQL()
@@ -327,12 +460,12 @@ func (c *Collection) Iterator() (*Iterator, error) {
 		log.Println("database [Iterator]:", sql)
 	}
 
-	rows, err := c.sess.Query(sql, values...)
+	rows, err := c.queryContext(ctx, sql, values...)
 	if err != nil {
 		return nil, err
 	}
 
-	return &Iterator{rows, c.props}, nil
+	return &Iterator{rows, c.meta}, nil
 }
 
 // GetAll receives a pointer to an empty slice of models and retrieves all the
@@ -358,7 +491,7 @@ func (c *Collection) GetAll(models interface{}) error {
 
 	dest := reflect.MakeSlice(t, 0, 0)
 
-	it, err := c.Iterator()
+	it, err := c.openIterator(context.Background())
 	if err != nil {
 		return err
 	}
@@ -377,6 +510,10 @@ func (c *Collection) GetAll(models interface{}) error {
 		dest = reflect.Append(dest, model)
 	}
 
+	if err := c.preload(dest); err != nil {
+		return err
+	}
+
 	v.Set(dest)
 
 	return nil
@@ -385,10 +522,14 @@ func (c *Collection) GetAll(models interface{}) error {
 // First returns the first model that matches the collection. If no one is found
 // it will return ErrNoSuchEntity and it won't touch model.
 func (c *Collection) First(instance Model) error {
+	if c.err != nil {
+		return c.err
+	}
 	c = c.Limit(1)
 
-	modelProps := updatedProps(c.props, instance)
+	modelProps := c.meta.modelProps(instance)
 	b := &sqlBuilder{
+		dialect:    c.dialect,
 		table:      c.model.TableName(),
 		conditions: c.conditions,
 		props:      modelProps,
@@ -396,6 +537,7 @@ func (c *Collection) First(instance Model) error {
 		offset:     c.offset,
 		orders:     c.orders,
 		alias:      c.alias,
+		joins:      c.sqlJoins(),
 	}
 
 	statement, values := b.SelectSQL()
@@ -403,11 +545,8 @@ func (c *Collection) First(instance Model) error {
 		log.Println("database [First]:", statement)
 	}
 
-	var pointers []interface{}
-	for _, prop := range modelProps {
-		pointers = append(pointers, prop.Pointer)
-	}
-	if err := c.sess.QueryRow(statement, values...).Scan(pointers...); err != nil {
+	pointers := c.meta.scanPointers(instance)
+	if err := c.queryRow(statement, values...).Scan(pointers...); err != nil {
 		if err == sql.ErrNoRows {
 			return ErrNoSuchEntity
 		}
@@ -415,17 +554,26 @@ func (c *Collection) First(instance Model) error {
 		return err
 	}
 
-	modelProps = updatedProps(c.props, instance)
+	modelProps = c.meta.modelProps(instance)
+	if err := instance.Tracking().AfterGet(modelProps); err != nil {
+		return err
+	}
 
-	return instance.Tracking().AfterGet(modelProps)
+	return c.preloadOne(instance)
 }
 
 // Count queries the number of rows that the collection matches.
 func (c *Collection) Count() (int64, error) {
+	if c.err != nil {
+		return 0, c.err
+	}
+
 	b := &sqlBuilder{
+		dialect:    c.dialect,
 		table:      c.model.TableName(),
 		conditions: c.conditions,
 		alias:      c.alias,
+		joins:      c.sqlJoins(),
 	}
 
 	sql, values := b.SelectSQLCols("COUNT(*)")
@@ -434,7 +582,7 @@ func (c *Collection) Count() (int64, error) {
 	}
 
 	var n int64
-	if err := c.sess.QueryRow(sql, values...).Scan(&n); err != nil {
+	if err := c.queryRow(sql, values...).Scan(&n); err != nil {
 		return 0, err
 	}
 
@@ -443,15 +591,14 @@ func (c *Collection) Count() (int64, error) {
 
 // GetMulti queries multiple rows and return all of them in a list. Keys should
 // be a list of primary keys to retrieve and models should be a pointer to an empty
-// slice of models. If any of the primary keys is not found a MultiError will be returned.
+// slice of models. Models with a composite primary key should use a list of Key
+// values instead. If any of the primary keys is not found a MultiError will be returned.
 // You can check the error type for MultiError and then loop over the list of errors, they will
 // be in the same order as the keys and they will have nil's when the row is found. The result
 // list will also have the same length as keys with nil's filled when the row is not found.
 func (c *Collection) GetMulti(keys interface{}, models interface{}) error {
 	v := reflect.ValueOf(models)
 	t := reflect.TypeOf(models)
-	keyst := reflect.TypeOf(keys)
-	keysv := reflect.ValueOf(keys)
 
 	if v.Kind() != reflect.Ptr {
 		return fmt.Errorf("database: pass a pointer to a slice of models to GetMulti")
@@ -462,29 +609,15 @@ func (c *Collection) GetMulti(keys interface{}, models interface{}) error {
 		return fmt.Errorf("database: pass a slice of models to GetMulti")
 	}
 
-	if keyst.Kind() != reflect.Slice {
-		return fmt.Errorf("database: pass a slice of keys to GetMulti")
-	}
-	keyst = keyst.Elem()
-	if keyst.Kind() != reflect.Int64 && keyst.Kind() != reflect.String {
-		return fmt.Errorf("database: pass a slice of string/int64 keys to GetMulti")
+	list, err := c.meta.keysOf(keys)
+	if err != nil {
+		return err
 	}
-	if keysv.Len() == 0 {
+	if len(list) == 0 {
 		return nil
 	}
 
-	var pk *Property
-	for _, prop := range c.props {
-		if prop.PrimaryKey {
-			if pk != nil {
-				return fmt.Errorf("database: cannot use GetMulti with multiple primary keys")
-			}
-
-			pk = prop
-		}
-	}
-
-	c = c.Filter(fmt.Sprintf("%s IN", pk.Name), keys)
+	c = c.Clone().FilterCond(c.keysCondition(list))
 
 	fetch := reflect.New(t)
 	fetch.Elem().Set(reflect.MakeSlice(t, 0, 0))
@@ -492,53 +625,24 @@ func (c *Collection) GetMulti(keys interface{}, models interface{}) error {
 		return err
 	}
 
-	stringKeys := map[string]reflect.Value{}
-	intKeys := map[int64]reflect.Value{}
-
-	var merr MultiError
+	byKey := map[string]reflect.Value{}
 	for i := 0; i < fetch.Elem().Len(); i++ {
 		model := fetch.Elem().Index(i)
-
-		pk := model.Elem().FieldByName(pk.Field).Interface()
-		switch v := pk.(type) {
-		case string:
-			stringKeys[v] = model
-		case int64:
-			intKeys[v] = model
-
-		default:
-			panic("should not reach here")
-		}
+		byKey[keyString(KeyOf(model.Interface().(Model)))] = model
 	}
 
-	results := reflect.MakeSlice(t, 0, 0)
-	for i := 0; i < keysv.Len(); i++ {
-		switch v := keysv.Index(i).Interface().(type) {
-		case string:
-			model, ok := stringKeys[v]
-			if !ok {
-				merr = append(merr, ErrNoSuchEntity)
-				results = reflect.Append(results, reflect.Zero(t.Elem()))
-				continue
-			}
-
-			merr = append(merr, nil)
-			results = reflect.Append(results, model)
-
-		case int64:
-			model, ok := intKeys[v]
-			if !ok {
-				merr = append(merr, ErrNoSuchEntity)
-				results = reflect.Append(results, reflect.Zero(t.Elem()))
-				continue
-			}
-
-			merr = append(merr, nil)
-			results = reflect.Append(results, model)
-
-		default:
-			panic("should not reach here")
+	var merr MultiError
+	results := reflect.MakeSlice(t, 0, len(list))
+	for _, key := range list {
+		model, ok := byKey[keyString(key)]
+		if !ok {
+			merr = append(merr, ErrNoSuchEntity)
+			results = reflect.Append(results, reflect.Zero(t.Elem()))
+			continue
 		}
+
+		merr = append(merr, nil)
+		results = reflect.Append(results, model)
 	}
 
 	v.Set(results)
@@ -553,32 +657,116 @@ func (c *Collection) GetMulti(keys interface{}, models interface{}) error {
 // value it may have to the value "1".
 func (c *Collection) Truncate() error {
 	b := &sqlBuilder{
-		table: c.model.TableName(),
+		dialect: c.dialect,
+		table:   c.model.TableName(),
 	}
 
-	statement := b.TruncateSQL()
-	if c.debug {
-		log.Println("database [Truncate]:", statement)
+	for _, statement := range b.TruncateSQL() {
+		if c.debug {
+			log.Println("database [Truncate]:", statement)
+		}
+
+		if _, err := c.exec(statement); err != nil {
+			return err
+		}
 	}
 
-	if _, err := c.sess.Exec(statement); err != nil {
-		return err
+	return nil
+}
+
+// FilterExists applies the global FilterExists condition to this collection. See
+// the global function for documentation.
+func (c *Collection) FilterExists(sub *Collection, join string) *Collection {
+	if sub.err != nil {
+		return c.fail(sub.err)
 	}
 
-	statement = b.ResetAutoIncrementSQL()
-	if c.debug {
-		log.Println("database [Truncate]:", statement)
+	return c.FilterCond(FilterExists(sub, join))
+}
+
+// FilterNotExists applies the global FilterNotExists condition to this collection. See
+// the global function for documentation.
+func (c *Collection) FilterNotExists(sub *Collection, join string) *Collection {
+	if sub.err != nil {
+		return c.fail(sub.err)
 	}
 
-	if _, err := c.sess.Exec(statement); err != nil {
-		return err
+	return c.FilterCond(FilterNotExists(sub, join))
+}
+
+// FilterIn applies the global FilterIn condition to this collection. See
+// the global function for documentation.
+func (c *Collection) FilterIn(column string, sub *Collection, subColumn string) *Collection {
+	column, subColumn, err := c.inColumns(column, sub, subColumn)
+	if err != nil {
+		return c.fail(err)
 	}
 
-	return nil
+	return c.FilterCond(FilterIn(column, sub, subColumn))
 }
 
-// FilterExists applies the global FilterExists condition to this collection. See
+// FilterNotIn applies the global FilterNotIn condition to this collection. See
 // the global function for documentation.
-func (c *Collection) FilterExists(sub *Collection, join string) *Collection {
-	return c.FilterCond(FilterExists(sub, join))
+func (c *Collection) FilterNotIn(column string, sub *Collection, subColumn string) *Collection {
+	column, subColumn, err := c.inColumns(column, sub, subColumn)
+	if err != nil {
+		return c.fail(err)
+	}
+
+	return c.FilterCond(FilterNotIn(column, sub, subColumn))
+}
+
+// inColumns validates the columns of FilterIn and FilterNotIn in their collections.
+func (c *Collection) inColumns(column string, sub *Collection, subColumn string) (string, string, error) {
+	if sub.err != nil {
+		return "", "", sub.err
+	}
+	column, err := c.Column(column)
+	if err != nil {
+		return "", "", err
+	}
+	subColumn, err = sub.Column(subColumn)
+	if err != nil {
+		return "", "", err
+	}
+
+	return column, subColumn, nil
+}
+
+func init() {
+	fields.Pointer = func(collection, instance interface{}, name string) (interface{}, error) {
+		return collection.(*Collection).fieldPointer(instance.(Model), name)
+	}
+}
+
+// fieldPointer returns a pointer to the field of the instance stored in a column,
+// for the fixtures of databasetest. It accepts the name of the column or the name
+// of the Go field, the same as Column, but they cannot be qualified.
+func (c *Collection) fieldPointer(instance Model, name string) (interface{}, error) {
+	if modelt, instancet := reflect.TypeOf(c.model), reflect.TypeOf(instance); modelt != instancet {
+		return nil, fmt.Errorf("database: expected instance of %s and got a instance of %s", modelt, instancet)
+	}
+
+	i := c.meta.fieldPosition(unquoteColumn(name))
+	if i < 0 {
+		return nil, fmt.Errorf("database: unknown column %s in model %T", name, c.model)
+	}
+
+	return c.meta.modelProps(instance)[i].Pointer, nil
+}
+
+func (c *Collection) exec(query string, args ...interface{}) (sql.Result, error) {
+	return c.sess.Exec(c.dialect.Rebind(query), args...)
+}
+
+func (c *Collection) query(query string, args ...interface{}) (*sql.Rows, error) {
+	return c.sess.Query(c.dialect.Rebind(query), args...)
+}
+
+func (c *Collection) queryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	return c.sess.QueryContext(ctx, c.dialect.Rebind(query), args...)
+}
+
+func (c *Collection) queryRow(query string, args ...interface{}) *sql.Row {
+	return c.sess.QueryRow(c.dialect.Rebind(query), args...)
 }
@@ -787,3 +787,209 @@ func TestFilterExistsAliasesAfterTheFilter(t *testing.T) {
 
 	require.EqualValues(t, models[0].ID, 1)
 }
+
+func TestFilterNotExists(t *testing.T) {
+	initDatabase(t)
+	defer closeDatabase()
+
+	parent := new(testingRelParent)
+	require.Nil(t, testingsRelParent.Put(parent))
+
+	child := &testingRelChild{
+		Parent: parent.ID,
+		Foo:    "foo-value",
+	}
+	require.Nil(t, testingsRelChild.Put(child))
+
+	otherParent := new(testingRelParent)
+	require.Nil(t, testingsRelParent.Put(otherParent))
+
+	var models []*testingRelParent
+	require.Nil(t, testingsRelParent.Alias("p").FilterNotExists(testingsRelChild.Alias("c"), "p.id = c.parent").GetAll(&models))
+
+	require.Len(t, models, 1)
+
+	require.EqualValues(t, models[0].ID, 2)
+}
+
+func TestFilterIn(t *testing.T) {
+	initDatabase(t)
+	defer closeDatabase()
+
+	require.Nil(t, testingsRelParent.Put(new(testingRelParent)))
+	require.Nil(t, testingsRelParent.Put(new(testingRelParent)))
+	require.Nil(t, testingsRelParent.Put(new(testingRelParent)))
+
+	require.Nil(t, testingsRelChild.Put(&testingRelChild{Parent: 1, Foo: "foo"}))
+	require.Nil(t, testingsRelChild.Put(&testingRelChild{Parent: 3, Foo: "bar"}))
+
+	var models []*testingRelParent
+	require.Nil(t, testingsRelParent.FilterIn("id", testingsRelChild.Clone().Filter("foo", "bar"), "parent").GetAll(&models))
+
+	require.Len(t, models, 1)
+
+	require.EqualValues(t, models[0].ID, 3)
+}
+
+func TestFilterNotIn(t *testing.T) {
+	initDatabase(t)
+	defer closeDatabase()
+
+	require.Nil(t, testingsRelParent.Put(new(testingRelParent)))
+	require.Nil(t, testingsRelParent.Put(new(testingRelParent)))
+	require.Nil(t, testingsRelParent.Put(new(testingRelParent)))
+
+	require.Nil(t, testingsRelChild.Put(&testingRelChild{Parent: 1, Foo: "foo"}))
+	require.Nil(t, testingsRelChild.Put(&testingRelChild{Parent: 3, Foo: "bar"}))
+
+	var models []*testingRelParent
+	require.Nil(t, testingsRelParent.Alias("p").FilterNotIn("p.id", testingsRelChild.Clone().Alias("c"), "c.parent").GetAll(&models))
+
+	require.Len(t, models, 1)
+
+	require.EqualValues(t, models[0].ID, 2)
+}
+
+func TestEmbeddedStructs(t *testing.T) {
+	initDatabase(t)
+	defer closeDatabase()
+
+	m := &testingEmbeddedModel{
+		Billing: testingAddress{
+			Street: "foo street",
+			City:   "foo city",
+		},
+	}
+	m.UpdatedBy = "foo"
+	require.Nil(t, testingsEmbedded.Put(m))
+	require.EqualValues(t, 1, m.ID)
+
+	other := &testingEmbeddedModel{
+		ID: 1,
+	}
+	require.Nil(t, testingsEmbedded.Get(other))
+	require.Equal(t, "foo", other.UpdatedBy)
+	require.Equal(t, "foo street", other.Billing.Street)
+	require.Equal(t, "foo city", other.Billing.City)
+
+	other.Billing.City = "bar city"
+	require.Nil(t, testingsEmbedded.Put(other))
+
+	var models []*testingEmbeddedModel
+	require.Nil(t, testingsEmbedded.Filter("billing_city", "bar city").GetAll(&models))
+	require.Len(t, models, 1)
+	require.Equal(t, "foo street", models[0].Billing.Street)
+}
+
+func TestGetMultiEmbedded(t *testing.T) {
+	initDatabase(t)
+	defer closeDatabase()
+
+	m := new(testingEmbeddedModel)
+	m.Billing.City = "foo"
+	require.Nil(t, testingsEmbedded.Put(m))
+
+	m = new(testingEmbeddedModel)
+	m.Billing.City = "bar"
+	require.Nil(t, testingsEmbedded.Put(m))
+
+	var models []*testingEmbeddedModel
+	require.Nil(t, testingsEmbedded.GetMulti([]int64{2, 1}, &models))
+
+	require.Len(t, models, 2)
+	require.Equal(t, "bar", models[0].Billing.City)
+	require.Equal(t, "foo", models[1].Billing.City)
+}
+
+func BenchmarkGetAll(b *testing.B) {
+	initDatabase(b)
+	defer closeDatabase()
+
+	for i := 0; i < 1000; i++ {
+		require.Nil(b, testingsAuto.Put(&testingAutoModel{Name: "foo"}))
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var models []*testingAutoModel
+		if err := testingsAuto.GetAll(&models); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestMapper(t *testing.T) {
+	initDatabase(t)
+	defer closeDatabase()
+
+	m := &testingMapperModel{
+		Code: "foo",
+		Name: "foo name",
+	}
+	require.Nil(t, testingsMapper.Put(m))
+
+	m.Name = "bar name"
+	require.Nil(t, testingsMapper.Put(m))
+
+	other := &testingMapperModel{
+		Code: "foo",
+	}
+	require.Nil(t, testingsMapper.Get(other))
+	require.Equal(t, "bar name", other.Name)
+	require.EqualValues(t, 1, other.Tracking().StoredRevision())
+
+	var models []*testingMapperModel
+	require.Nil(t, testingsMapper.GetAll(&models))
+	require.Len(t, models, 1)
+	require.Equal(t, "bar name", models[0].Name)
+}
+
+func TestOrderSorters(t *testing.T) {
+	skipSQLite(t)
+	initDatabase(t)
+	defer closeDatabase()
+
+	require.Nil(t, testings.Put(&testingModel{Code: "a", Name: "Foo"}))
+	require.Nil(t, testings.Put(&testingModel{Code: "b", Name: "bar"}))
+	require.Nil(t, testings.Put(&testingModel{Code: "c", Name: "baz"}))
+
+	var models []*testingModel
+	require.Nil(t, testings.Clone().OrderSorter(OrderField("code", "b", "c", "a")).GetAll(&models))
+	require.Len(t, models, 3)
+	require.Equal(t, "b", models[0].Code)
+	require.Equal(t, "c", models[1].Code)
+	require.Equal(t, "a", models[2].Code)
+
+	models = nil
+	require.Nil(t, testings.Clone().OrderSorter(OrderCollate("-name", "utf8mb4_general_ci")).GetAll(&models))
+	require.Len(t, models, 3)
+	require.Equal(t, "a", models[0].Code)
+	require.Equal(t, "c", models[1].Code)
+	require.Equal(t, "b", models[2].Code)
+
+	var first, second []*testingModel
+	require.Nil(t, testings.Clone().OrderSorter(OrderRandomSeed(3)).GetAll(&first))
+	require.Nil(t, testings.Clone().OrderSorter(OrderRandomSeed(3)).GetAll(&second))
+	require.Equal(t, first, second)
+}
+
+func TestFieldPointer(t *testing.T) {
+	c := newCollection(new(Database), new(testingModel))
+
+	m := &testingModel{Code: "foo"}
+	ptr, err := c.fieldPointer(m, "Name")
+	require.Nil(t, err)
+	*ptr.(*string) = "bar"
+	require.Equal(t, "bar", m.Name)
+
+	ptr, err = c.fieldPointer(m, "code")
+	require.Nil(t, err)
+	require.Equal(t, "foo", *ptr.(*string))
+
+	_, err = c.fieldPointer(m, "foo")
+	require.EqualError(t, err, "database: unknown column foo in model *database.testingModel")
+
+	_, err = c.fieldPointer(new(testingAutoModel), "name")
+	require.EqualError(t, err, "database: expected instance of *database.testingModel and got a instance of *database.testingAutoModel")
+}
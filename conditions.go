@@ -3,6 +3,7 @@ package database
 import (
 	"fmt"
 	"reflect"
+	"regexp"
 	"strings"
 )
 
@@ -16,20 +17,64 @@ type Condition interface {
 	Values() []interface{}
 }
 
+var (
+	inRe        = regexp.MustCompile(`(?i)\s(NOT\s+)?IN$`)
+	operatorRe  = regexp.MustCompile(`(?i)\s(=|!=|<>|<=>|<|<=|>|>=|LIKE|NOT\s+LIKE|REGEXP|NOT\s+REGEXP)$`)
+	collationRe = regexp.MustCompile(`^[a-zA-Z0-9_]+$`)
+)
+
 // Filter applies a new simple filter to the collection. There are multiple types
 // of simple filters depending on the SQL you pass to it:
 //
-//   Filter("foo", "bar")
-//   Filter("foo >", 3)
-//   Filter("foo LIKE", "%bar%")
-//   Filter("DATE_DIFF(?, mycolumn) > 30", time.Now())
+//	Filter("foo", "bar")
+//	Filter("foo >", 3)
+//	Filter("foo LIKE", "%bar%")
+//	Filter("foo IN", []int64{3, 4})
+//	Filter("foo NOT IN", []int64{3, 4})
+//	Filter("DATE_DIFF(?, mycolumn) > 30", time.Now())
+//
+// Use FilterExpr for custom SQL that needs more than one value.
+//
+// IN filters with an empty list of values will match no rows, and NOT IN filters
+// with an empty list will match every row.
+//
+// Filter panics if the SQL is malformed instead of sending an invalid query to
+// the database: invalid column names, unknown operators, more than one placeholder,
+// IN filters without a slice or comparisons with nil (use FilterIsNil instead).
+// Custom SQL with a placeholder and expressions before the operator, like
+// "LOWER(name) =", are not validated, never build them with user input.
 func Filter(sql string, value interface{}) Condition {
+	sql = strings.TrimSpace(sql)
+	if sql == "" {
+		panic("empty Filter SQL")
+	}
+
 	var queryValues []interface{}
 	if !strings.Contains(sql, " ") {
+		if !columnRe.MatchString(unquoteColumn(sql)) {
+			panic(fmt.Sprintf("invalid column name in Filter: %s", sql))
+		}
+		if value == nil {
+			panic(fmt.Sprintf("cannot compare %s with nil in Filter, use FilterIsNil instead", sql))
+		}
+
 		sql = fmt.Sprintf("%s = ?", sql)
 		queryValues = []interface{}{value}
-	} else if strings.Contains(sql, " IN") {
+	} else if match := inRe.FindStringSubmatchIndex(sql); match != nil {
+		if column := sql[:match[0]]; !isFilterOperand(column) {
+			panic(fmt.Sprintf("invalid column name in Filter: %s", column))
+		}
 		v := reflect.ValueOf(value)
+		if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+			panic(fmt.Sprintf("Filter with IN requires a slice of values: %s", sql))
+		}
+
+		if v.Len() == 0 {
+			if match[2] != -1 {
+				return &sqlCondition{sql: "1 = 1"}
+			}
+			return &sqlCondition{sql: "1 = 0"}
+		}
 
 		placeholders := make([]string, v.Len())
 		for i := 0; i < v.Len(); i++ {
@@ -39,21 +84,102 @@ func Filter(sql string, value interface{}) Condition {
 		sql = fmt.Sprintf("%s (%s)", sql, strings.Join(placeholders, ", "))
 
 	} else if !strings.Contains(sql, "?") {
+		match := operatorRe.FindStringIndex(sql)
+		if match == nil {
+			panic(fmt.Sprintf("unknown operator in Filter: %s", sql))
+		}
+		if column := sql[:match[0]]; !isFilterOperand(column) {
+			panic(fmt.Sprintf("invalid column name in Filter: %s", column))
+		}
+		if value == nil {
+			panic(fmt.Sprintf("cannot compare with nil in Filter, use FilterIsNil instead: %s", sql))
+		}
+
 		sql = fmt.Sprintf("%s ?", sql)
 		queryValues = []interface{}{value}
 	} else {
+		if strings.Count(sql, "?") > 1 {
+			panic(fmt.Sprintf("Filter accepts a single placeholder, use FilterCond for more: %s", sql))
+		}
+
 		queryValues = []interface{}{value}
 	}
 
 	return &sqlCondition{sql, queryValues}
 }
 
+// isFilterOperand returns true if the left side of an operator in Filter is a
+// column name or an expression with parenthesis.
+func isFilterOperand(column string) bool {
+	return strings.Contains(column, "(") || columnRe.MatchString(unquoteColumn(column))
+}
+
+// In checks that the column is one of the values. If the list of values is empty
+// no row will match.
+func In(column string, values interface{}) Condition {
+	return Filter(fmt.Sprintf("%s IN", column), values)
+}
+
+// NotIn checks that the column is not one of the values. If the list of values is
+// empty every row will match.
+func NotIn(column string, values interface{}) Condition {
+	return Filter(fmt.Sprintf("%s NOT IN", column), values)
+}
+
+// Between checks that the column is between both values, inclusive.
+func Between(column string, from, to interface{}) Condition {
+	checkColumn(column)
+	return &sqlCondition{
+		sql:    fmt.Sprintf("%s BETWEEN ? AND ?", column),
+		values: []interface{}{from, to},
+	}
+}
+
+// Not negates a condition.
+func Not(cond Condition) Condition {
+	if cond.SQL() == "" {
+		return new(sqlCondition)
+	}
+
+	return &sqlCondition{
+		sql:    fmt.Sprintf("NOT (%s)", cond.SQL()),
+		values: cond.Values(),
+	}
+}
+
+// ILike applies a case-insensitive LIKE filter to the column, independent of the
+// collation it has. Use EscapeLike to clean the value before adding the wildcards.
+func ILike(column, value string) Condition {
+	checkColumn(column)
+	return &sqlCondition{
+		sql:    fmt.Sprintf("LOWER(%s) LIKE LOWER(?)", column),
+		values: []interface{}{value},
+	}
+}
+
+// EqualCollate compares the column with the value using a specific collation
+// instead of the one of the column. For example you can use utf8mb4_general_ci
+// to compare case-insensitive a column that is stored with utf8mb4_bin.
+func EqualCollate(column string, value interface{}, collation string) Condition {
+	checkColumn(column)
+	if !collationRe.MatchString(collation) {
+		panic(fmt.Sprintf("invalid collation: %s", collation))
+	}
+
+	return &sqlCondition{
+		sql:    fmt.Sprintf("%s = ? COLLATE %s", column, collation),
+		values: []interface{}{value},
+	}
+}
+
 // CompareJSON creates a new condition that checks if a value inside a JSON
 // object of a column is equal to the provided value.
 func CompareJSON(column, path string, value interface{}) Condition {
+	checkColumn(column)
+
 	return &sqlCondition{
-		sql:    fmt.Sprintf("JSON_EXTRACT(%s, '%s') = ?", column, path),
-		values: []interface{}{value},
+		sql:    fmt.Sprintf("JSON_EXTRACT(%s, ?) = ?", column),
+		values: []interface{}{path, value},
 	}
 }
 
@@ -72,8 +198,55 @@ func FilterExists(sub *Collection, join string) Condition {
 		panic("join SQL statement is required to FilterExists")
 	}
 
-	sub = sub.Clone().FilterCond(&sqlCondition{join, nil})
+	sql, values := subquerySQL(sub.Clone().FilterCond(&sqlCondition{join, nil}), "NULL")
+	return &sqlCondition{fmt.Sprintf("EXISTS (%s)", sql), values}
+}
+
+// FilterNotExists checks that a subquery does not match for each row before accepting it.
+// It is the opposite of FilterExists, see its documentation for details about the join statement.
+func FilterNotExists(sub *Collection, join string) Condition {
+	if join == "" {
+		panic("join SQL statement is required to FilterNotExists")
+	}
+
+	sql, values := subquerySQL(sub.Clone().FilterCond(&sqlCondition{join, nil}), "NULL")
+	return &sqlCondition{fmt.Sprintf("NOT EXISTS (%s)", sql), values}
+}
+
+// FilterIn checks that the column of each row is one of the values of subColumn
+// returned by the subquery. The filters of the sub collection will be applied to
+// the subquery. MySQL does not support LIMIT in IN subqueries, so it will panic if
+// the sub collection has a limit or an offset. Use aliases if the column names of
+// both collections are ambiguous.
+func FilterIn(column string, sub *Collection, subColumn string) Condition {
+	checkColumn(column)
+	checkInSubquery(sub)
+	subColumn = sub.mustColumn(subColumn)
+	sql, values := subquerySQL(sub, subColumn)
+	return &sqlCondition{fmt.Sprintf("%s IN (%s)", column, sql), values}
+}
+
+// FilterNotIn checks that the column of each row is not one of the values of subColumn
+// returned by the subquery. Take into account that if the subquery returns any NULL
+// value no row will match; filter them in the sub collection to avoid it. As in
+// FilterIn, the sub collection cannot have a limit or an offset.
+func FilterNotIn(column string, sub *Collection, subColumn string) Condition {
+	checkColumn(column)
+	checkInSubquery(sub)
+	subColumn = sub.mustColumn(subColumn)
+	sql, values := subquerySQL(sub, subColumn)
+	return &sqlCondition{fmt.Sprintf("%s NOT IN (%s)", column, sql), values}
+}
+
+func checkInSubquery(sub *Collection) {
+	if sub.limit > 0 || sub.offset > 0 {
+		panic("IN subqueries cannot have a limit or an offset")
+	}
+}
+
+func subquerySQL(sub *Collection, cols ...string) (string, []interface{}) {
 	b := &sqlBuilder{
+		dialect:    sub.dialect,
 		table:      sub.model.TableName(),
 		conditions: sub.conditions,
 		props:      sub.props,
@@ -81,9 +254,9 @@ func FilterExists(sub *Collection, join string) Condition {
 		offset:     sub.offset,
 		orders:     sub.orders,
 		alias:      sub.alias,
+		joins:      sub.sqlJoins(),
 	}
-	sql, values := b.SelectSQLCols("NULL")
-	return &sqlCondition{fmt.Sprintf("EXISTS (%s)", sql), values}
+	return b.SelectSQLCols(cols...)
 }
 
 type sqlCondition struct {
@@ -148,6 +321,8 @@ func EscapeLike(str string) string {
 
 // FilterIsNil filter rows with with NULL in the column.
 func FilterIsNil(column string) Condition {
+	checkColumn(column)
+
 	return &sqlCondition{
 		sql: fmt.Sprintf("%s IS NULL", column),
 	}
@@ -155,6 +330,8 @@ func FilterIsNil(column string) Condition {
 
 // FilterIsNotNil filter rows with with something other than NULL in the column.
 func FilterIsNotNil(column string) Condition {
+	checkColumn(column)
+
 	return &sqlCondition{
 		sql: fmt.Sprintf("%s IS NOT NULL", column),
 	}
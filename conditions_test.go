@@ -0,0 +1,131 @@
+package database
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFilterInSQL(t *testing.T) {
+	sub := newCollection(new(Database), new(testingRelChild)).Alias("c").Filter("c.foo", "bar")
+	cond := FilterIn("p.id", sub, "c.parent")
+
+	require.Equal(t, "p.id IN (SELECT c.parent FROM testing_relchild AS c WHERE c.foo = ?)", cond.SQL())
+	require.Equal(t, []interface{}{"bar"}, cond.Values())
+}
+
+func TestFilterInLimitPanics(t *testing.T) {
+	require.PanicsWithValue(t, "IN subqueries cannot have a limit or an offset", func() {
+		FilterIn("id", newCollection(new(Database), new(testingRelChild)).Limit(10), "parent")
+	})
+	require.PanicsWithValue(t, "IN subqueries cannot have a limit or an offset", func() {
+		FilterNotIn("id", newCollection(new(Database), new(testingRelChild)).Offset(10), "parent")
+	})
+}
+
+func TestFilterNotInSQL(t *testing.T) {
+	sub := newCollection(new(Database), new(testingRelChild)).Filter("foo", "bar")
+	cond := FilterNotIn("id", sub, "parent")
+
+	require.Equal(t, "id NOT IN (SELECT parent FROM testing_relchild WHERE foo = ?)", cond.SQL())
+	require.Equal(t, []interface{}{"bar"}, cond.Values())
+}
+
+func TestFilterNotExistsSQL(t *testing.T) {
+	sub := newCollection(new(Database), new(testingRelChild)).Alias("c").Filter("c.foo", "bar")
+	cond := FilterNotExists(sub, "p.id = c.parent")
+
+	require.Equal(t, "NOT EXISTS (SELECT NULL FROM testing_relchild AS c WHERE c.foo = ? AND p.id = c.parent)", cond.SQL())
+	require.Equal(t, []interface{}{"bar"}, cond.Values())
+}
+
+func TestFilterNotExistsRequiresJoin(t *testing.T) {
+	require.PanicsWithValue(t, "join SQL statement is required to FilterNotExists", func() {
+		FilterNotExists(newCollection(new(Database), new(testingRelChild)), "")
+	})
+}
+
+func TestFilterSQL(t *testing.T) {
+	tests := []struct {
+		sql    string
+		value  interface{}
+		result string
+		values []interface{}
+	}{
+		{"foo", "bar", "foo = ?", []interface{}{"bar"}},
+		{"a.`foo`", "bar", "a.`foo` = ?", []interface{}{"bar"}},
+		{"foo >", 3, "foo > ?", []interface{}{3}},
+		{"foo NOT LIKE", "%bar%", "foo NOT LIKE ?", []interface{}{"%bar%"}},
+		{"foo IN", []int64{3, 4}, "foo IN (?, ?)", []interface{}{int64(3), int64(4)}},
+		{"foo NOT IN", []string{"bar"}, "foo NOT IN (?)", []interface{}{"bar"}},
+		{"foo IN", []int64{}, "1 = 0", nil},
+		{"foo NOT IN", []int64{}, "1 = 1", nil},
+		{"DATE_DIFF(?, foo) > 30", 3, "DATE_DIFF(?, foo) > 30", []interface{}{3}},
+	}
+	for _, test := range tests {
+		cond := Filter(test.sql, test.value)
+		require.Equal(t, test.result, cond.SQL())
+		require.Equal(t, test.values, cond.Values())
+	}
+}
+
+func TestFilterMalformedPanics(t *testing.T) {
+	require.PanicsWithValue(t, "empty Filter SQL", func() {
+		Filter(" ", 3)
+	})
+	require.PanicsWithValue(t, "invalid column name in Filter: foo;", func() {
+		Filter("foo;", 3)
+	})
+	require.PanicsWithValue(t, "unknown operator in Filter: foo EQUALS", func() {
+		Filter("foo EQUALS", 3)
+	})
+	require.PanicsWithValue(t, "Filter with IN requires a slice of values: foo IN", func() {
+		Filter("foo IN", 3)
+	})
+	require.PanicsWithValue(t, "Filter accepts a single placeholder, use FilterCond for more: foo BETWEEN ? AND ?", func() {
+		Filter("foo BETWEEN ? AND ?", 3)
+	})
+	require.PanicsWithValue(t, "cannot compare foo with nil in Filter, use FilterIsNil instead", func() {
+		Filter("foo", nil)
+	})
+}
+
+func TestIn(t *testing.T) {
+	cond := In("foo", []string{"bar", "baz"})
+	require.Equal(t, "foo IN (?, ?)", cond.SQL())
+	require.Equal(t, []interface{}{"bar", "baz"}, cond.Values())
+
+	cond = NotIn("foo", []string{})
+	require.Equal(t, "1 = 1", cond.SQL())
+	require.Empty(t, cond.Values())
+}
+
+func TestBetween(t *testing.T) {
+	cond := Between("foo", 3, 5)
+	require.Equal(t, "foo BETWEEN ? AND ?", cond.SQL())
+	require.Equal(t, []interface{}{3, 5}, cond.Values())
+}
+
+func TestNot(t *testing.T) {
+	cond := Not(Or([]Condition{Filter("foo", 3), Filter("bar >", 4)}))
+	require.Equal(t, "NOT ((foo = ? OR bar > ?))", cond.SQL())
+	require.Equal(t, []interface{}{3, 4}, cond.Values())
+
+	require.Empty(t, Not(And(nil)).SQL())
+}
+
+func TestILike(t *testing.T) {
+	cond := ILike("foo", "%Bar%")
+	require.Equal(t, "LOWER(foo) LIKE LOWER(?)", cond.SQL())
+	require.Equal(t, []interface{}{"%Bar%"}, cond.Values())
+}
+
+func TestEqualCollate(t *testing.T) {
+	cond := EqualCollate("foo", "Bar", "utf8mb4_general_ci")
+	require.Equal(t, "foo = ? COLLATE utf8mb4_general_ci", cond.SQL())
+	require.Equal(t, []interface{}{"Bar"}, cond.Values())
+
+	require.PanicsWithValue(t, "invalid collation: foo; DROP", func() {
+		EqualCollate("foo", "Bar", "foo; DROP")
+	})
+}
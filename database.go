@@ -1,6 +1,7 @@
 package database
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"log"
@@ -11,8 +12,13 @@ import (
 
 // Database represents a reusable connection to a remote MySQL database.
 type Database struct {
-	sess  *sql.DB
-	debug bool
+	sess    *sql.DB
+	debug   bool
+	dialect Dialect
+	driver  string
+
+	// Connections received with FromDB are not closed by us.
+	borrowed bool
 }
 
 // Open starts a new connection to a remote MySQL database using the provided credentials
@@ -27,21 +33,45 @@ func Open(credentials Credentials, options ...Option) (*Database, error) {
 	}
 
 	var err error
-	db.sess, err = sql.Open("mysql", credentials.String())
+	dialect := dialectOf(db)
+	driver := db.driver
+	if driver == "" {
+		driver = dialect.DriverName()
+	}
+	db.sess, err = sql.Open(driver, dialect.DSN(credentials))
 	if err != nil {
-		return nil, fmt.Errorf("database: cannot connect to mysql: %s", err)
+		return nil, fmt.Errorf("database: cannot connect to %s: %s", driver, err)
 	}
 
 	db.sess.SetMaxOpenConns(3)
 	db.sess.SetMaxIdleConns(0)
 
 	if err := db.sess.Ping(); err != nil {
-		return nil, fmt.Errorf("database: cannot ping mysql: %s", err)
+		return nil, fmt.Errorf("database: cannot ping %s: %s", driver, err)
 	}
 
 	return db, nil
 }
 
+// FromDB wraps a connection pool opened by other library, like instrumented drivers,
+// connection poolers or test doubles. The pool is used as it is, without changing
+// its limits nor checking the connection. Close won't close it, it should be closed
+// by its owner when it is no longer used.
+//
+// Options configure the library the same way as in Open; pass WithDialect if the
+// database is not MySQL.
+func FromDB(sess *sql.DB, options ...Option) *Database {
+	db := &Database{
+		sess:     sess,
+		borrowed: true,
+	}
+	for _, option := range options {
+		option(db)
+	}
+
+	return db
+}
+
 // Collection prepares a new collection using the table name of the model. It won't
 // make any query, it only prepares the structs.
 func (db *Database) Collection(model Model) *Collection {
@@ -49,27 +79,70 @@ func (db *Database) Collection(model Model) *Collection {
 }
 
 // Close the connection. You should not use a database after closing it, nor any
-// of its generated collections.
+// of its generated collections. Connections received with FromDB are not closed.
 func (db *Database) Close() {
+	if db.borrowed {
+		return
+	}
+
 	db.sess.Close()
 }
 
 // Exec runs a raw SQL query in the database and returns nothing. It is
 // recommended to use Collections instead.
+//
+// Params can be positional values for "?" placeholders or a single Named value
+// with the values of named parameters like ":name". See FilterExpr for details
+// about named parameters.
 func (db *Database) Exec(query string, params ...interface{}) error {
-	_, err := db.sess.Exec(query, params...)
+	query, params, err := bindParams(dialectOf(db), query, params)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.sess.Exec(dialectOf(db).Rebind(query), params...)
 	return err
 }
 
 // QueryRow runs a raw SQL query in the database and returns the raw row from
-// MySQL. It is recommended to use Collections instead.
+// MySQL. It is recommended to use Collections instead. Use QueryRowNamed for
+// queries with named parameters.
 func (db *Database) QueryRow(query string, params ...interface{}) *sql.Row {
-	return db.sess.QueryRow(query, params...)
+	return db.sess.QueryRow(dialectOf(db).Rebind(query), params...)
+}
+
+// QueryRowNamed runs a raw SQL query with named parameters like ":name" in the
+// database and returns the raw row. It returns an error if the parameters cannot
+// be bound to the query. See FilterExpr for details about named parameters.
+func (db *Database) QueryRowNamed(query string, params Named) (*sql.Row, error) {
+	query, values, err := bindNamed(dialectOf(db), query, params)
+	if err != nil {
+		return nil, err
+	}
+
+	return db.QueryRow(query, values...), nil
+}
+
+// Conn returns a single dedicated connection from the pool. It is needed for
+// operations that depend on the session state, like locks or variables, and
+// should be closed when done to return it to the pool.
+func (db *Database) Conn(ctx context.Context) (*sql.Conn, error) {
+	return db.sess.Conn(ctx)
 }
 
 // Option can be passed when opening a new connection to a database.
 type Option func(db *Database)
 
+// WithDriver is a database option that opens the connection with a different
+// database/sql driver than the default one of the dialect, for example an
+// instrumented wrapper of the MySQL driver registered with other name. It is
+// ignored by FromDB.
+func WithDriver(driver string) Option {
+	return func(db *Database) {
+		db.driver = driver
+	}
+}
+
 // WithDebug is a database option that enables debug logging in the library.
 func WithDebug(debug bool) Option {
 	return func(db *Database) {
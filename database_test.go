@@ -1,9 +1,12 @@
 package database
 
 import (
+	"database/sql"
 	"os"
+	"path/filepath"
 	"testing"
 
+	_ "github.com/mattn/go-sqlite3"
 	"github.com/stretchr/testify/require"
 )
 
@@ -14,6 +17,9 @@ var (
 	testingsHooker    *Collection
 	testingsRelParent *Collection
 	testingsRelChild  *Collection
+	testingsEmbedded  *Collection
+	testingsMapper    *Collection
+	testingsComposite *Collection
 )
 
 type testingModel struct {
@@ -68,6 +74,8 @@ type testingRelParent struct {
 	ModelTracking
 
 	ID int64 `db:"id,pk"`
+
+	Children []*testingRelChild `db:"-,hasmany=Parent"`
 }
 
 func (model *testingRelParent) TableName() string {
@@ -81,13 +89,103 @@ type testingRelChild struct {
 
 	Parent int64  `db:"parent"`
 	Foo    string `db:"foo"`
+
+	ParentModel *testingRelParent `db:"-,belongsto=Parent"`
 }
 
 func (model *testingRelChild) TableName() string {
 	return "testing_relchild"
 }
 
-func initDatabase(t *testing.T) {
+type testingAddress struct {
+	Street string `db:"street"`
+	City   string `db:"city"`
+}
+
+// TestingAudit should be exported to be embedded in the models.
+type TestingAudit struct {
+	UpdatedBy string `db:"updated_by"`
+}
+
+type testingEmbeddedModel struct {
+	ModelTracking
+	TestingAudit
+
+	ID      int64          `db:"id,pk"`
+	Billing testingAddress `db:",inline,prefix=billing_"`
+}
+
+func (model *testingEmbeddedModel) TableName() string {
+	return "testing_embedded"
+}
+
+type testingCompositeModel struct {
+	ModelTracking
+
+	ID   int64  `db:"id,pk"`
+	Code string `db:"code,pk"`
+	Name string `db:"name"`
+}
+
+func (model *testingCompositeModel) TableName() string {
+	return "testing_composite"
+}
+
+type testingMapperModel struct {
+	ModelTracking
+
+	Code string `db:"code,pk"`
+	Name string `db:"name"`
+}
+
+func (model *testingMapperModel) TableName() string {
+	return "testing"
+}
+
+func (model *testingMapperModel) DBColumns() []string {
+	return []string{"`revision`", "`code`", "`name`"}
+}
+
+func (model *testingMapperModel) DBValues() []interface{} {
+	return []interface{}{model.ModelTracking.Revision, model.Code, model.Name}
+}
+
+func (model *testingMapperModel) DBPointers() []interface{} {
+	return []interface{}{&model.ModelTracking.Revision, &model.Code, &model.Name}
+}
+
+// sqliteTables are the test tables of initDatabase in SQLite. Tables without an
+// integer key are stored WITHOUT ROWID to read them in primary key order like
+// InnoDB does.
+var sqliteTables = []string{
+	`CREATE TABLE testing (code TEXT PRIMARY KEY, name TEXT, revision INTEGER NOT NULL) WITHOUT ROWID`,
+	`CREATE TABLE testing_auto (id INTEGER PRIMARY KEY, name TEXT, revision INTEGER NOT NULL)`,
+	`CREATE TABLE testing_hooker (code TEXT PRIMARY KEY, executed BOOLEAN, changed TEXT, revision INTEGER NOT NULL) WITHOUT ROWID`,
+	`CREATE TABLE testing_relparent (id INTEGER PRIMARY KEY, revision INTEGER NOT NULL)`,
+	`CREATE TABLE testing_relchild (id INTEGER PRIMARY KEY, parent INTEGER, foo TEXT NOT NULL, revision INTEGER NOT NULL)`,
+	`CREATE TABLE testing_embedded (id INTEGER PRIMARY KEY, updated_by TEXT, billing_street TEXT, billing_city TEXT, revision INTEGER NOT NULL)`,
+	`CREATE TABLE testing_composite (id INTEGER NOT NULL, code TEXT NOT NULL, name TEXT, revision INTEGER NOT NULL, PRIMARY KEY(id, code)) WITHOUT ROWID`,
+}
+
+// testSQLite reports if the tests run against a SQLite database instead of the
+// MySQL server of docker-compose. Run them with TEST_DIALECT=sqlite.
+func testSQLite() bool {
+	return os.Getenv("TEST_DIALECT") == "sqlite"
+}
+
+// skipSQLite skips tests of features that are specific to MySQL.
+func skipSQLite(t testing.TB) {
+	if testSQLite() {
+		t.Skip("test requires a MySQL database")
+	}
+}
+
+func initDatabase(t testing.TB) {
+	if testSQLite() {
+		initSQLiteDatabase(t)
+		return
+	}
+
 	var err error
 	testDB, err = Open(Credentials{
 		User:      "dev-user",
@@ -160,11 +258,59 @@ func initDatabase(t *testing.T) {
   `)
 	require.Nil(t, err)
 
+	require.Nil(t, testDB.Exec(`DROP TABLE IF EXISTS testing_embedded`))
+	err = testDB.Exec(`
+    CREATE TABLE testing_embedded (
+      id INT(11) NOT NULL AUTO_INCREMENT,
+      updated_by VARCHAR(191),
+      billing_street VARCHAR(191),
+      billing_city VARCHAR(191),
+      revision INT(11) NOT NULL,
+
+      PRIMARY KEY(id)
+    ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin;
+  `)
+	require.Nil(t, err)
+
+	require.Nil(t, testDB.Exec(`DROP TABLE IF EXISTS testing_composite`))
+	err = testDB.Exec(`
+    CREATE TABLE testing_composite (
+      id INT(11) NOT NULL AUTO_INCREMENT,
+      code VARCHAR(191) NOT NULL,
+      name VARCHAR(191),
+      revision INT(11) NOT NULL,
+
+      PRIMARY KEY(id, code)
+    ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin;
+  `)
+	require.Nil(t, err)
+
+	initCollections()
+}
+
+func initSQLiteDatabase(t testing.TB) {
+	var err error
+	testDB, err = Open(Credentials{
+		Database: filepath.Join(t.TempDir(), "test.db") + "?_journal_mode=WAL&_busy_timeout=5000",
+	}, WithDialect(SQLite), WithDebug(os.Getenv("DEBUG") == "true"))
+	require.Nil(t, err)
+
+	for _, table := range sqliteTables {
+		require.Nil(t, testDB.Exec(table))
+	}
+
+	initCollections()
+}
+
+func initCollections() {
 	testings = testDB.Collection(new(testingModel))
 	testingsAuto = testDB.Collection(new(testingAutoModel))
 	testingsHooker = testDB.Collection(new(testingHooker))
 	testingsRelParent = testDB.Collection(new(testingRelParent))
 	testingsRelChild = testDB.Collection(new(testingRelChild))
+	testingsEmbedded = testDB.Collection(new(testingEmbeddedModel))
+	testingsMapper = testDB.Collection(new(testingMapperModel))
+	testingsComposite = testDB.Collection(new(testingCompositeModel))
 }
 
 func closeDatabase() {
@@ -186,3 +332,23 @@ func TestQueryRow(t *testing.T) {
 	require.NoError(t, row.Scan(&name))
 	require.Equal(t, "test", name)
 }
+
+func TestFromDB(t *testing.T) {
+	sess, err := sql.Open("mysql", "dev-user:dev-password@tcp(localhost:1)/test")
+	require.Nil(t, err)
+	defer sess.Close()
+
+	db := FromDB(sess, WithDialect(PostgreSQL), WithDebug(true))
+	require.Equal(t, PostgreSQL, db.Collection(new(testingModel)).dialect)
+	require.True(t, db.debug)
+
+	db.Close()
+	err = sess.Ping()
+	require.Error(t, err)
+	require.NotEqual(t, "sql: database is closed", err.Error())
+}
+
+func TestOpenWithDriver(t *testing.T) {
+	_, err := Open(Credentials{}, WithDriver("unknown-driver"))
+	require.EqualError(t, err, `database: cannot connect to unknown-driver: sql: unknown driver "unknown-driver" (forgotten import?)`)
+}
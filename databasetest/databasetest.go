@@ -0,0 +1,41 @@
+// Package databasetest provides a fake in-memory database to unit test code that
+// uses collections without a MySQL server:
+//
+//	func TestCreateHotel(t *testing.T) {
+//		db := databasetest.New(new(models.Hotel), new(models.Room))
+//
+//		require.NoError(t, CreateHotel(db, "Hotel Foo"))
+//		...
+//	}
+//
+// The fake implements a database/sql driver that understands the SQL generated by
+// the collections: Get, Put, Delete, GetAll, GetMulti, First, Count and Iterator
+// with the standard filters, orders, limits and offsets. Revision checks and hooks
+// work the same as with MySQL as they are implemented by the collections.
+//
+// Column types are ignored and values are stored as they are received. Strings are
+// compared byte by byte like with a binary collation. Joins, subqueries, full-text
+// search, GROUP BY and most functions are not supported and return an error; test
+// those queries against a real database.
+package databasetest
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/altipla-consulting/database"
+)
+
+// New opens an empty fake database and creates the tables of the models. Each call
+// returns an independent database, so tests can run in parallel. It panics if the
+// schema of any model cannot be generated with Collection.CreateTableSQL.
+func New(models ...database.Model) *database.Database {
+	db := database.FromDB(sql.OpenDB(&connector{store: newStore()}))
+	for _, model := range models {
+		if err := db.CreateTable(model); err != nil {
+			panic(fmt.Sprintf("cannot create the table of %T: %s", model, err))
+		}
+	}
+
+	return db
+}
@@ -0,0 +1,362 @@
+package databasetest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/altipla-consulting/database"
+)
+
+type testingModel struct {
+	database.ModelTracking
+
+	Code    string     `db:"code,pk"`
+	Name    string     `db:"name"`
+	Age     int64      `db:"age"`
+	Deleted *time.Time `db:"deleted"`
+}
+
+func (model *testingModel) TableName() string {
+	return "testing"
+}
+
+type testingAutoModel struct {
+	database.ModelTracking
+
+	ID   int64  `db:"id,pk"`
+	Name string `db:"name"`
+}
+
+func (model *testingAutoModel) TableName() string {
+	return "testing_auto"
+}
+
+type testingHooker struct {
+	database.ModelTracking
+
+	Code     string `db:"code,pk"`
+	Executed bool   `db:"executed"`
+	Changed  string `db:"changed"`
+}
+
+func (model *testingHooker) TableName() string {
+	return "testing_hooker"
+}
+
+func (model *testingHooker) OnBeforePutHook() error {
+	model.Changed = "changed"
+
+	return nil
+}
+
+func (model *testingHooker) OnAfterPutHook() error {
+	model.Executed = true
+
+	return nil
+}
+
+type testingCompositeModel struct {
+	database.ModelTracking
+
+	ID   int64  `db:"id,pk"`
+	Code string `db:"code,pk"`
+	Name string `db:"name"`
+}
+
+func (model *testingCompositeModel) TableName() string {
+	return "testing_composite"
+}
+
+func initFake(t *testing.T) *database.Database {
+	db := New(new(testingModel), new(testingAutoModel), new(testingHooker), new(testingCompositeModel))
+
+	testings := db.Collection(new(testingModel))
+	for _, m := range []*testingModel{
+		{Code: "a", Name: "foo", Age: 30},
+		{Code: "b", Name: "bar", Age: 20},
+		{Code: "c", Name: "baz", Age: 40},
+		{Code: "d", Name: "qux", Age: 10},
+	} {
+		require.NoError(t, testings.Put(m))
+	}
+
+	return db
+}
+
+func codes(models []*testingModel) []string {
+	var result []string
+	for _, m := range models {
+		result = append(result, m.Code)
+	}
+	return result
+}
+
+func TestGetPut(t *testing.T) {
+	db := initFake(t)
+	testings := db.Collection(new(testingModel))
+
+	m := &testingModel{Code: "b"}
+	require.NoError(t, testings.Get(m))
+	require.Equal(t, "bar", m.Name)
+	require.EqualValues(t, 20, m.Age)
+	require.Nil(t, m.Deleted)
+	require.EqualValues(t, 0, m.Tracking().StoredRevision())
+
+	deleted := time.Date(2019, time.January, 2, 3, 4, 5, 0, time.UTC)
+	m.Name = "changed"
+	m.Deleted = &deleted
+	require.NoError(t, testings.Put(m))
+
+	other := &testingModel{Code: "b"}
+	require.NoError(t, testings.Get(other))
+	require.Equal(t, "changed", other.Name)
+	require.Equal(t, deleted, *other.Deleted)
+	require.EqualValues(t, 1, other.Tracking().StoredRevision())
+}
+
+func TestGetNotFound(t *testing.T) {
+	db := initFake(t)
+
+	m := &testingModel{Code: "unknown", Name: "untouched"}
+	require.Equal(t, database.ErrNoSuchEntity, db.Collection(new(testingModel)).Get(m))
+	require.Equal(t, "untouched", m.Name)
+}
+
+func TestPutDuplicated(t *testing.T) {
+	db := initFake(t)
+
+	err := db.Collection(new(testingModel)).Put(&testingModel{Code: "a"})
+	require.EqualError(t, err, "databasetest: duplicate entry 'a' for key PRIMARY in table testing")
+}
+
+func TestPutConcurrentTransaction(t *testing.T) {
+	db := initFake(t)
+	testings := db.Collection(new(testingModel))
+
+	first := &testingModel{Code: "a"}
+	require.NoError(t, testings.Get(first))
+	second := &testingModel{Code: "a"}
+	require.NoError(t, testings.Get(second))
+
+	first.Name = "first"
+	require.NoError(t, testings.Put(first))
+
+	second.Name = "second"
+	require.Equal(t, database.ErrConcurrentTransaction, testings.Put(second))
+}
+
+func TestPutAutoIncrement(t *testing.T) {
+	db := initFake(t)
+	autos := db.Collection(new(testingAutoModel))
+
+	first := &testingAutoModel{Name: "foo"}
+	require.NoError(t, autos.Put(first))
+	require.EqualValues(t, 1, first.ID)
+
+	explicit := &testingAutoModel{ID: 10, Name: "bar"}
+	require.NoError(t, autos.Put(explicit))
+
+	next := &testingAutoModel{Name: "baz"}
+	require.NoError(t, autos.Put(next))
+	require.EqualValues(t, 11, next.ID)
+
+	require.NoError(t, autos.Truncate())
+	again := &testingAutoModel{Name: "qux"}
+	require.NoError(t, autos.Put(again))
+	require.EqualValues(t, 1, again.ID)
+}
+
+func TestPutHooks(t *testing.T) {
+	db := initFake(t)
+	hookers := db.Collection(new(testingHooker))
+
+	m := &testingHooker{Code: "foo"}
+	require.NoError(t, hookers.Put(m))
+	require.True(t, m.Executed)
+
+	other := &testingHooker{Code: "foo"}
+	require.NoError(t, hookers.Get(other))
+	require.Equal(t, "changed", other.Changed)
+	require.False(t, other.Executed)
+}
+
+func TestDelete(t *testing.T) {
+	db := initFake(t)
+	testings := db.Collection(new(testingModel))
+
+	require.NoError(t, testings.Delete(&testingModel{Code: "a"}))
+	require.Equal(t, database.ErrNoSuchEntity, testings.Get(&testingModel{Code: "a"}))
+
+	n, err := testings.Count()
+	require.NoError(t, err)
+	require.EqualValues(t, 3, n)
+}
+
+func TestGetAllFilters(t *testing.T) {
+	db := initFake(t)
+
+	tests := []struct {
+		name     string
+		c        *database.Collection
+		expected []string
+	}{
+		{"equal", db.Collection(new(testingModel)).Filter("name", "bar"), []string{"b"}},
+		{"field name", db.Collection(new(testingModel)).Filter("Name", "bar"), []string{"b"}},
+		{"greater", db.Collection(new(testingModel)).Filter("age >", 20), []string{"a", "c"}},
+		{"not equal", db.Collection(new(testingModel)).Filter("age !=", 20), []string{"a", "c", "d"}},
+		{"in", db.Collection(new(testingModel)).Filter("code IN", []string{"a", "d", "z"}), []string{"a", "d"}},
+		{"not in", db.Collection(new(testingModel)).Filter("code NOT IN", []string{"a", "d"}), []string{"b", "c"}},
+		{"empty in", db.Collection(new(testingModel)).Filter("code IN", []string{}), nil},
+		{"like", db.Collection(new(testingModel)).Filter("name LIKE", "ba%"), []string{"b", "c"}},
+		{"not like", db.Collection(new(testingModel)).Filter("name NOT LIKE", "ba%"), []string{"a", "d"}},
+		{"nil", db.Collection(new(testingModel)).FilterIsNil("deleted"), []string{"a", "b", "c", "d"}},
+		{"not nil", db.Collection(new(testingModel)).FilterIsNotNil("deleted"), nil},
+		{"between", db.Collection(new(testingModel)).FilterCond(database.Between("age", 20, 30)), []string{"a", "b"}},
+		{"ilike", db.Collection(new(testingModel)).FilterCond(database.ILike("name", "BA%")), []string{"b", "c"}},
+		{"collate", db.Collection(new(testingModel)).FilterCond(database.EqualCollate("name", "FOO", "utf8mb4_general_ci")), []string{"a"}},
+		{"alias", db.Collection(new(testingModel)).Alias("t").Filter("t.age <", 15), []string{"d"}},
+		{
+			"or",
+			db.Collection(new(testingModel)).FilterCond(database.Or([]database.Condition{
+				database.Filter("code", "a"),
+				database.Filter("age >=", 40),
+			})),
+			[]string{"a", "c"},
+		},
+		{
+			"not",
+			db.Collection(new(testingModel)).FilterCond(database.Not(database.In("code", []string{"a", "b"}))),
+			[]string{"c", "d"},
+		},
+		{
+			"expr",
+			db.Collection(new(testingModel)).FilterExpr("age > :min AND code IN (:codes)", database.Named{
+				"min":   15,
+				"codes": []string{"a", "b", "d"},
+			}),
+			[]string{"a", "b"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var models []*testingModel
+			require.NoError(t, test.c.Order("code").GetAll(&models))
+			require.Equal(t, test.expected, codes(models))
+		})
+	}
+}
+
+func TestGetAllOrderLimit(t *testing.T) {
+	db := initFake(t)
+
+	var models []*testingModel
+	require.NoError(t, db.Collection(new(testingModel)).Order("-age").Limit(2).Offset(1).GetAll(&models))
+	require.Equal(t, []string{"a", "b"}, codes(models))
+
+	models = nil
+	require.NoError(t, db.Collection(new(testingModel)).OrderSorter(database.OrderField("code", "c", "a")).Order("code").GetAll(&models))
+	require.Equal(t, []string{"b", "d", "c", "a"}, codes(models))
+
+	models = nil
+	require.NoError(t, db.Collection(new(testingModel)).Offset(10).Limit(5).GetAll(&models))
+	require.Empty(t, models)
+}
+
+func TestFirstCount(t *testing.T) {
+	db := initFake(t)
+	testings := db.Collection(new(testingModel)).Filter("age >", 15)
+
+	m := new(testingModel)
+	require.NoError(t, testings.Clone().Order("age").First(m))
+	require.Equal(t, "b", m.Code)
+
+	n, err := testings.Count()
+	require.NoError(t, err)
+	require.EqualValues(t, 3, n)
+
+	require.Equal(t, database.ErrNoSuchEntity, db.Collection(new(testingModel)).Filter("age >", 100).First(new(testingModel)))
+}
+
+func TestGetMulti(t *testing.T) {
+	db := initFake(t)
+
+	var models []*testingModel
+	require.NoError(t, db.Collection(new(testingModel)).GetMulti([]string{"c", "a"}, &models))
+	require.Equal(t, []string{"c", "a"}, codes(models))
+
+	err := db.Collection(new(testingModel)).GetMulti([]string{"a", "z"}, &models)
+	require.Error(t, err)
+}
+
+func TestGetMultiComposite(t *testing.T) {
+	db := initFake(t)
+	composites := db.Collection(new(testingCompositeModel))
+
+	require.NoError(t, composites.Put(&testingCompositeModel{ID: 1, Code: "a", Name: "foo"}))
+	require.NoError(t, composites.Put(&testingCompositeModel{ID: 1, Code: "b", Name: "bar"}))
+	require.NoError(t, composites.Put(&testingCompositeModel{ID: 2, Code: "a", Name: "baz"}))
+
+	var models []*testingCompositeModel
+	require.NoError(t, composites.GetMulti([]database.Key{{int64(2), "a"}, {int64(1), "b"}}, &models))
+	require.Len(t, models, 2)
+	require.Equal(t, "baz", models[0].Name)
+	require.Equal(t, "bar", models[1].Name)
+}
+
+func TestIterator(t *testing.T) {
+	db := initFake(t)
+
+	it, err := db.Collection(new(testingModel)).Order("age").Iterator()
+	require.NoError(t, err)
+	defer it.Close()
+
+	var result []string
+	for {
+		m := new(testingModel)
+		if err := it.Next(m); err == database.ErrDone {
+			break
+		} else {
+			require.NoError(t, err)
+		}
+		result = append(result, m.Code)
+	}
+	require.Equal(t, []string{"d", "b", "a", "c"}, result)
+}
+
+func TestForEachBatch(t *testing.T) {
+	db := initFake(t)
+
+	var batches [][]string
+	err := db.Collection(new(testingModel)).ForEachBatch(3, func(batch []database.Model) error {
+		var codes []string
+		for _, m := range batch {
+			codes = append(codes, m.(*testingModel).Code)
+		}
+		batches = append(batches, codes)
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, [][]string{{"a", "b", "c"}, {"d"}}, batches)
+}
+
+func TestIndependentDatabases(t *testing.T) {
+	first := initFake(t)
+	second := New(new(testingModel))
+
+	n, err := second.Collection(new(testingModel)).Count()
+	require.NoError(t, err)
+	require.Zero(t, n)
+
+	n, err = first.Collection(new(testingModel)).Count()
+	require.NoError(t, err)
+	require.EqualValues(t, 4, n)
+}
+
+func TestUnsupportedQuery(t *testing.T) {
+	db := initFake(t)
+
+	err := db.Exec(`SELECT code FROM testing GROUP BY code`)
+	require.EqualError(t, err, `databasetest: unsupported query near "GROUP": SELECT code FROM testing GROUP BY code`)
+}
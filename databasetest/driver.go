@@ -0,0 +1,175 @@
+package databasetest
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"io"
+)
+
+type connector struct {
+	store *store
+}
+
+func (c *connector) Connect(ctx context.Context) (driver.Conn, error) {
+	return &conn{store: c.store}, nil
+}
+
+func (c *connector) Driver() driver.Driver {
+	return fakeDriver{}
+}
+
+// fakeDriver is only returned to satisfy driver.Connector; connections are
+// always opened through the connector that owns the store.
+type fakeDriver struct{}
+
+func (d fakeDriver) Open(name string) (driver.Conn, error) {
+	return nil, fmt.Errorf("databasetest: open the fake database with New")
+}
+
+type conn struct {
+	store *store
+	tx    *tx
+}
+
+func (c *conn) Prepare(query string) (driver.Stmt, error) {
+	if _, err := c.store.parse(query); err != nil {
+		return nil, err
+	}
+	return &stmt{conn: c, query: query}, nil
+}
+
+func (c *conn) Close() error {
+	return nil
+}
+
+func (c *conn) Begin() (driver.Tx, error) {
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+func (c *conn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if c.tx != nil {
+		return nil, fmt.Errorf("databasetest: there is already a transaction in progress")
+	}
+	c.tx = &tx{conn: c, snapshot: c.store.snapshot()}
+	return c.tx, nil
+}
+
+func (c *conn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	values, err := namedValues(args)
+	if err != nil {
+		return nil, err
+	}
+	return c.store.exec(query, values)
+}
+
+func (c *conn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	values, err := namedValues(args)
+	if err != nil {
+		return nil, err
+	}
+	columns, data, err := c.store.query(query, values)
+	if err != nil {
+		return nil, err
+	}
+	return &rows{columns: columns, data: data}, nil
+}
+
+// namedValues returns the positional values of the query. Byte slices are copied
+// because the caller owns them and may reuse the memory.
+func namedValues(args []driver.NamedValue) ([]interface{}, error) {
+	values := make([]interface{}, len(args))
+	for i, arg := range args {
+		if arg.Name != "" {
+			return nil, fmt.Errorf("databasetest: named arguments are not supported: %s", arg.Name)
+		}
+		if b, ok := arg.Value.([]byte); ok {
+			arg.Value = append([]byte(nil), b...)
+		}
+		values[i] = arg.Value
+	}
+	return values, nil
+}
+
+// tx restores the tables to the state they had when the transaction started if
+// it is rolled back. Changes of other connections in the meantime are lost too.
+type tx struct {
+	conn     *conn
+	snapshot map[string]*table
+}
+
+func (t *tx) Commit() error {
+	t.conn.tx = nil
+	return nil
+}
+
+func (t *tx) Rollback() error {
+	t.conn.store.restore(t.snapshot)
+	t.conn.tx = nil
+	return nil
+}
+
+type stmt struct {
+	conn  *conn
+	query string
+}
+
+func (s *stmt) Close() error {
+	return nil
+}
+
+func (s *stmt) NumInput() int {
+	return -1
+}
+
+func (s *stmt) Exec(args []driver.Value) (driver.Result, error) {
+	return s.conn.ExecContext(context.Background(), s.query, valuesToNamed(args))
+}
+
+func (s *stmt) Query(args []driver.Value) (driver.Rows, error) {
+	return s.conn.QueryContext(context.Background(), s.query, valuesToNamed(args))
+}
+
+func (s *stmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	return s.conn.ExecContext(ctx, s.query, args)
+}
+
+func (s *stmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	return s.conn.QueryContext(ctx, s.query, args)
+}
+
+func valuesToNamed(args []driver.Value) []driver.NamedValue {
+	named := make([]driver.NamedValue, len(args))
+	for i, arg := range args {
+		named[i] = driver.NamedValue{Ordinal: i + 1, Value: arg}
+	}
+	return named
+}
+
+type rows struct {
+	columns []string
+	data    [][]interface{}
+	pos     int
+}
+
+func (r *rows) Columns() []string {
+	return r.columns
+}
+
+func (r *rows) Close() error {
+	return nil
+}
+
+func (r *rows) Next(dest []driver.Value) error {
+	if r.pos >= len(r.data) {
+		return io.EOF
+	}
+	for i, value := range r.data[r.pos] {
+		if b, ok := value.([]byte); ok {
+			value = append([]byte(nil), b...)
+		}
+		dest[i] = value
+	}
+	r.pos++
+	return nil
+}
@@ -0,0 +1,574 @@
+package databasetest
+
+import (
+	"fmt"
+	"math/rand"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// scope resolves the columns and parameters of an expression while it is evaluated
+// against a row of a table.
+type scope struct {
+	table *table
+	alias string
+	row   []interface{}
+	args  []interface{}
+}
+
+type expr interface {
+	eval(s *scope) (interface{}, error)
+}
+
+type literalExpr struct {
+	value interface{}
+}
+
+func (e *literalExpr) eval(s *scope) (interface{}, error) {
+	return e.value, nil
+}
+
+type paramExpr struct {
+	index int
+}
+
+func (e *paramExpr) eval(s *scope) (interface{}, error) {
+	if e.index >= len(s.args) {
+		return nil, fmt.Errorf("databasetest: missing value for placeholder %d", e.index+1)
+	}
+	return s.args[e.index], nil
+}
+
+type columnExpr struct {
+	qualifier, name string
+}
+
+func (e *columnExpr) eval(s *scope) (interface{}, error) {
+	if s.table == nil {
+		return nil, fmt.Errorf("databasetest: unknown column %s", e.name)
+	}
+	if e.qualifier != "" && e.qualifier != s.table.name && e.qualifier != s.alias {
+		return nil, fmt.Errorf("databasetest: unknown column %s.%s", e.qualifier, e.name)
+	}
+	idx, ok := s.table.index[e.name]
+	if !ok {
+		return nil, fmt.Errorf("databasetest: unknown column %s in table %s", e.name, s.table.name)
+	}
+	return s.row[idx], nil
+}
+
+type tupleExpr struct {
+	items []expr
+}
+
+func (e *tupleExpr) eval(s *scope) (interface{}, error) {
+	values := make(tuple, len(e.items))
+	for i, item := range e.items {
+		var err error
+		if values[i], err = item.eval(s); err != nil {
+			return nil, err
+		}
+	}
+	return values, nil
+}
+
+// tuple is the value of a row constructor like (a, b).
+type tuple []interface{}
+
+// Boolean results follow the three-valued logic of SQL: true, false or nil
+// when the result is unknown because of a NULL operand.
+
+type logicExpr struct {
+	op          string
+	left, right expr
+}
+
+func (e *logicExpr) eval(s *scope) (interface{}, error) {
+	left, err := evalBool(e.left, s)
+	if err != nil {
+		return nil, err
+	}
+	if e.op == "AND" && left == false || e.op == "OR" && left == true {
+		return left, nil
+	}
+
+	right, err := evalBool(e.right, s)
+	if err != nil {
+		return nil, err
+	}
+	if e.op == "AND" && right == false || e.op == "OR" && right == true {
+		return right, nil
+	}
+	if left == nil || right == nil {
+		return nil, nil
+	}
+	return right, nil
+}
+
+type notExpr struct {
+	value expr
+}
+
+func (e *notExpr) eval(s *scope) (interface{}, error) {
+	value, err := evalBool(e.value, s)
+	if err != nil || value == nil {
+		return nil, err
+	}
+	return !value.(bool), nil
+}
+
+type compareExpr struct {
+	op          string
+	left, right expr
+}
+
+func (e *compareExpr) eval(s *scope) (interface{}, error) {
+	left, err := e.left.eval(s)
+	if err != nil {
+		return nil, err
+	}
+	right, err := e.right.eval(s)
+	if err != nil {
+		return nil, err
+	}
+
+	if e.op == "<=>" {
+		if left == nil || right == nil {
+			return left == nil && right == nil, nil
+		}
+		cmp, _ := compare(left, right)
+		return cmp == 0, nil
+	}
+
+	cmp, ok := compare(left, right)
+	if !ok {
+		return nil, nil
+	}
+	switch e.op {
+	case "=":
+		return cmp == 0, nil
+	case "!=", "<>":
+		return cmp != 0, nil
+	case "<":
+		return cmp < 0, nil
+	case "<=":
+		return cmp <= 0, nil
+	case ">":
+		return cmp > 0, nil
+	case ">=":
+		return cmp >= 0, nil
+	}
+	panic("should not reach here")
+}
+
+type isNullExpr struct {
+	value expr
+	not   bool
+}
+
+func (e *isNullExpr) eval(s *scope) (interface{}, error) {
+	value, err := e.value.eval(s)
+	if err != nil {
+		return nil, err
+	}
+	return (value == nil) != e.not, nil
+}
+
+type inExpr struct {
+	value expr
+	list  []expr
+	not   bool
+}
+
+func (e *inExpr) eval(s *scope) (interface{}, error) {
+	value, err := e.value.eval(s)
+	if err != nil {
+		return nil, err
+	}
+
+	var unknown bool
+	for _, item := range e.list {
+		other, err := item.eval(s)
+		if err != nil {
+			return nil, err
+		}
+		cmp, ok := compare(value, other)
+		if !ok {
+			unknown = true
+			continue
+		}
+		if cmp == 0 {
+			return !e.not, nil
+		}
+	}
+	if unknown {
+		return nil, nil
+	}
+	return e.not, nil
+}
+
+type betweenExpr struct {
+	value, from, to expr
+	not             bool
+}
+
+func (e *betweenExpr) eval(s *scope) (interface{}, error) {
+	cond := &logicExpr{
+		op:    "AND",
+		left:  &compareExpr{op: ">=", left: e.value, right: e.from},
+		right: &compareExpr{op: "<=", left: e.value, right: e.to},
+	}
+	if e.not {
+		return (&notExpr{cond}).eval(s)
+	}
+	return cond.eval(s)
+}
+
+type likeExpr struct {
+	value, pattern expr
+	not            bool
+}
+
+func (e *likeExpr) eval(s *scope) (interface{}, error) {
+	value, err := e.value.eval(s)
+	if err != nil {
+		return nil, err
+	}
+	pattern, err := e.pattern.eval(s)
+	if err != nil {
+		return nil, err
+	}
+	if value == nil || pattern == nil {
+		return nil, nil
+	}
+
+	re, err := likeRegexp(toString(pattern))
+	if err != nil {
+		return nil, err
+	}
+	return re.MatchString(toString(value)) != e.not, nil
+}
+
+// likeRegexp translates the wildcards of a LIKE pattern to a regular expression.
+func likeRegexp(pattern string) (*regexp.Regexp, error) {
+	var re strings.Builder
+	re.WriteString("(?s)^")
+	for i := 0; i < len(pattern); i++ {
+		switch ch := pattern[i]; {
+		case ch == '\\' && i+1 < len(pattern):
+			i++
+			re.WriteString(regexp.QuoteMeta(pattern[i : i+1]))
+		case ch == '%':
+			re.WriteString(".*")
+		case ch == '_':
+			re.WriteString(".")
+		default:
+			re.WriteString(regexp.QuoteMeta(pattern[i : i+1]))
+		}
+	}
+	re.WriteString("$")
+
+	return regexp.Compile(re.String())
+}
+
+type regexpExpr struct {
+	value, pattern expr
+	not            bool
+}
+
+func (e *regexpExpr) eval(s *scope) (interface{}, error) {
+	value, err := e.value.eval(s)
+	if err != nil {
+		return nil, err
+	}
+	pattern, err := e.pattern.eval(s)
+	if err != nil {
+		return nil, err
+	}
+	if value == nil || pattern == nil {
+		return nil, nil
+	}
+
+	re, err := regexp.Compile(toString(pattern))
+	if err != nil {
+		return nil, fmt.Errorf("databasetest: invalid regular expression: %s", err)
+	}
+	return re.MatchString(toString(value)) != e.not, nil
+}
+
+type collateExpr struct {
+	value     expr
+	collation string
+}
+
+// eval lowercases the strings of case-insensitive collations to compare them.
+func (e *collateExpr) eval(s *scope) (interface{}, error) {
+	value, err := e.value.eval(s)
+	if err != nil {
+		return nil, err
+	}
+	if str, ok := normalize(value).(string); ok && strings.HasSuffix(strings.ToLower(e.collation), "_ci") {
+		return strings.ToLower(str), nil
+	}
+	return value, nil
+}
+
+type arithmeticExpr struct {
+	op          string
+	left, right expr
+}
+
+func (e *arithmeticExpr) eval(s *scope) (interface{}, error) {
+	left, err := e.left.eval(s)
+	if err != nil {
+		return nil, err
+	}
+	right, err := e.right.eval(s)
+	if err != nil {
+		return nil, err
+	}
+	if left == nil || right == nil {
+		return nil, nil
+	}
+
+	a, b := toNumber(left), toNumber(right)
+	if ai, ok := a.(int64); ok {
+		if bi, ok := b.(int64); ok {
+			if e.op == "+" {
+				return ai + bi, nil
+			}
+			return ai - bi, nil
+		}
+	}
+	if e.op == "+" {
+		return toFloat(a) + toFloat(b), nil
+	}
+	return toFloat(a) - toFloat(b), nil
+}
+
+type callExpr struct {
+	name string
+	args []expr
+}
+
+var functions = map[string]func(args []interface{}) (interface{}, error){
+	"LOWER": func(args []interface{}) (interface{}, error) {
+		if len(args) != 1 || args[0] == nil {
+			return nil, nil
+		}
+		return strings.ToLower(toString(args[0])), nil
+	},
+	"UPPER": func(args []interface{}) (interface{}, error) {
+		if len(args) != 1 || args[0] == nil {
+			return nil, nil
+		}
+		return strings.ToUpper(toString(args[0])), nil
+	},
+	"COALESCE": func(args []interface{}) (interface{}, error) {
+		for _, arg := range args {
+			if arg != nil {
+				return arg, nil
+			}
+		}
+		return nil, nil
+	},
+	"NOW": func(args []interface{}) (interface{}, error) {
+		return time.Now().UTC().Truncate(time.Second), nil
+	},
+	"RAND": func(args []interface{}) (interface{}, error) {
+		return rand.Float64(), nil
+	},
+	"FIELD": func(args []interface{}) (interface{}, error) {
+		if len(args) == 0 || args[0] == nil {
+			return int64(0), nil
+		}
+		for i, arg := range args[1:] {
+			if cmp, ok := compare(args[0], arg); ok && cmp == 0 {
+				return int64(i + 1), nil
+			}
+		}
+		return int64(0), nil
+	},
+}
+
+func (e *callExpr) eval(s *scope) (interface{}, error) {
+	args := make([]interface{}, len(e.args))
+	for i, arg := range e.args {
+		var err error
+		if args[i], err = arg.eval(s); err != nil {
+			return nil, err
+		}
+	}
+	return functions[e.name](args)
+}
+
+// evalBool evaluates a condition and returns true, false or nil if unknown.
+func evalBool(e expr, s *scope) (interface{}, error) {
+	value, err := e.eval(s)
+	if err != nil || value == nil {
+		return nil, err
+	}
+	if b, ok := value.(bool); ok {
+		return b, nil
+	}
+	return toFloat(toNumber(value)) != 0, nil
+}
+
+// matches returns true if the row passes the WHERE condition.
+func matches(where expr, s *scope) (bool, error) {
+	if where == nil {
+		return true, nil
+	}
+	value, err := evalBool(where, s)
+	if err != nil {
+		return false, err
+	}
+	return value == true, nil
+}
+
+// normalize converts the values to a minimal set of types to compare them:
+// int64, float64, string, time.Time, tuple and nil.
+func normalize(value interface{}) interface{} {
+	switch v := value.(type) {
+	case bool:
+		if v {
+			return int64(1)
+		}
+		return int64(0)
+	case []byte:
+		return string(v)
+	case int:
+		return int64(v)
+	case int32:
+		return int64(v)
+	case uint64:
+		return int64(v)
+	case float32:
+		return float64(v)
+	}
+	return value
+}
+
+// compare returns the order of both values and false if any of them is NULL.
+// Numbers and strings are compared like MySQL does, converting the string to
+// a number. Strings are compared byte by byte like a binary collation.
+func compare(a, b interface{}) (int, bool) {
+	a, b = normalize(a), normalize(b)
+	if a == nil || b == nil {
+		return 0, false
+	}
+
+	switch av := a.(type) {
+	case tuple:
+		bv, ok := b.(tuple)
+		if !ok || len(av) != len(bv) {
+			return 0, false
+		}
+		for i := range av {
+			cmp, ok := compare(av[i], bv[i])
+			if !ok {
+				return 0, false
+			}
+			if cmp != 0 {
+				return cmp, true
+			}
+		}
+		return 0, true
+
+	case string:
+		switch bv := b.(type) {
+		case string:
+			return strings.Compare(av, bv), true
+		case time.Time:
+			return compareTimes(parseTime(av), bv), true
+		}
+
+	case time.Time:
+		switch bv := b.(type) {
+		case time.Time:
+			return compareTimes(av, bv), true
+		case string:
+			return compareTimes(av, parseTime(bv)), true
+		}
+	}
+
+	an, bn := toNumber(a), toNumber(b)
+	if ai, ok := an.(int64); ok {
+		if bi, ok := bn.(int64); ok {
+			switch {
+			case ai < bi:
+				return -1, true
+			case ai > bi:
+				return 1, true
+			}
+			return 0, true
+		}
+	}
+	af, bf := toFloat(an), toFloat(bn)
+	switch {
+	case af < bf:
+		return -1, true
+	case af > bf:
+		return 1, true
+	}
+	return 0, true
+}
+
+func compareTimes(a, b time.Time) int {
+	switch {
+	case a.Before(b):
+		return -1
+	case a.After(b):
+		return 1
+	}
+	return 0
+}
+
+func parseTime(value string) time.Time {
+	for _, layout := range []string{"2006-01-02 15:04:05", "2006-01-02", time.RFC3339Nano} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// toNumber returns the value as an int64 or float64. Strings that are not numbers
+// are converted to zero.
+func toNumber(value interface{}) interface{} {
+	switch v := normalize(value).(type) {
+	case int64, float64:
+		return v
+	case string:
+		if n, err := strconv.ParseInt(strings.TrimSpace(v), 10, 64); err == nil {
+			return n
+		}
+		f, _ := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		return f
+	case time.Time:
+		return v.Unix()
+	}
+	return int64(0)
+}
+
+func toFloat(value interface{}) float64 {
+	switch v := value.(type) {
+	case int64:
+		return float64(v)
+	case float64:
+		return v
+	}
+	return 0
+}
+
+func toString(value interface{}) string {
+	switch v := normalize(value).(type) {
+	case string:
+		return v
+	case time.Time:
+		return v.Format("2006-01-02 15:04:05")
+	}
+	return fmt.Sprint(normalize(value))
+}
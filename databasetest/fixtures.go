@@ -0,0 +1,225 @@
+package databasetest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"reflect"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/altipla-consulting/database"
+	"github.com/altipla-consulting/database/internal/fields"
+)
+
+// LoadFixtures inserts the rows of a JSON fixture in the tables of the models.
+// The fixture is an object with the name of each table and the list of rows to
+// insert in it. Rows are objects with the name of the columns or the Go fields of
+// the model:
+//
+//	{
+//	  "hotels": [
+//	    {"id": 1, "name": "Hotel Foo", "created_at": "2019-01-01T10:00:00Z"}
+//	  ],
+//	  "rooms": [
+//	    {"id": 1, "hotel_id": 1, "Name": "Room 101"}
+//	  ]
+//	}
+//
+// Tables are loaded in the order they appear in the fixture. Each row is stored
+// with Collection.Put, so hooks run and the revisions are tracked the same as in
+// the application. Values are decoded with encoding/json into the fields, so times
+// should be written in RFC 3339 format. If no models are passed the ones added with
+// database.RegisterModels are used.
+func LoadFixtures(db *database.Database, r io.Reader, models ...database.Model) error {
+	tables := fixtureTables(models)
+
+	decoder := json.NewDecoder(r)
+	if err := expectDelim(decoder, '{'); err != nil {
+		return err
+	}
+	for decoder.More() {
+		tok, err := decoder.Token()
+		if err != nil {
+			return fmt.Errorf("databasetest: cannot read fixture: %s", err)
+		}
+		table := tok.(string)
+
+		var rows []map[string]json.RawMessage
+		if err := decoder.Decode(&rows); err != nil {
+			return fmt.Errorf("databasetest: cannot read rows of table %s: %s", table, err)
+		}
+		values := make([]map[string]fixtureValue, len(rows))
+		for i, row := range rows {
+			values[i] = map[string]fixtureValue{}
+			for key, value := range row {
+				values[i][key] = func(ptr interface{}) error {
+					return json.Unmarshal(value, ptr)
+				}
+			}
+		}
+
+		if err := insertFixtureRows(db, tables, table, values); err != nil {
+			return err
+		}
+	}
+
+	return expectDelim(decoder, '}')
+}
+
+// LoadYAMLFixtures inserts the rows of a YAML fixture in the tables of the models.
+// It follows the same rules of LoadFixtures, with values decoded by gopkg.in/yaml.v3:
+//
+//	hotels:
+//	- id: 1
+//	  name: Hotel Foo
+//	  created_at: 2019-01-01T10:00:00Z
+//	rooms:
+//	- {id: 1, hotel_id: 1, Name: Room 101}
+func LoadYAMLFixtures(db *database.Database, r io.Reader, models ...database.Model) error {
+	tables := fixtureTables(models)
+
+	var doc yaml.Node
+	if err := yaml.NewDecoder(r).Decode(&doc); err != nil {
+		if err == io.EOF {
+			return nil
+		}
+		return fmt.Errorf("databasetest: cannot read fixture: %s", err)
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return fmt.Errorf("databasetest: invalid fixture, expected a map of tables in line %d", root.Line)
+	}
+	for i := 0; i < len(root.Content); i += 2 {
+		table := root.Content[i].Value
+
+		var rows []map[string]yaml.Node
+		if err := root.Content[i+1].Decode(&rows); err != nil {
+			return fmt.Errorf("databasetest: cannot read rows of table %s: %s", table, err)
+		}
+		values := make([]map[string]fixtureValue, len(rows))
+		for j, row := range rows {
+			values[j] = map[string]fixtureValue{}
+			for key, value := range row {
+				values[j][key] = value.Decode
+			}
+		}
+
+		if err := insertFixtureRows(db, tables, table, values); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// fixtureValue decodes the value of a column of the fixture into a field.
+type fixtureValue func(ptr interface{}) error
+
+// fixtureTables indexes the models by the name of their table. If there are no
+// models the registered ones are used.
+func fixtureTables(models []database.Model) map[string]database.Model {
+	if len(models) == 0 {
+		models = database.RegisteredModels()
+	}
+	tables := map[string]database.Model{}
+	for _, model := range models {
+		tables[model.TableName()] = model
+	}
+
+	return tables
+}
+
+func insertFixtureRows(db *database.Database, tables map[string]database.Model, table string, rows []map[string]fixtureValue) error {
+	model, ok := tables[table]
+	if !ok {
+		return fmt.Errorf("databasetest: no model for table %s in fixture", table)
+	}
+
+	c := db.Collection(model)
+	for i, row := range rows {
+		instance, err := fixtureInstance(c, model, row)
+		if err != nil {
+			return fmt.Errorf("databasetest: row %d of table %s: %s", i, table, err)
+		}
+		if err := c.Put(instance); err != nil {
+			return fmt.Errorf("databasetest: cannot insert row %d of table %s: %s", i, table, err)
+		}
+	}
+
+	return nil
+}
+
+// LoadFixtureFiles loads every fixture of the file system that matches the pattern,
+// in lexical order. Files with the .yaml or .yml extension are read with
+// LoadYAMLFixtures and the rest with LoadFixtures.
+//
+//	//go:embed testdata/fixtures/*.json
+//	var fixtures embed.FS
+//
+//	err := databasetest.LoadFixtureFiles(db, fixtures, "testdata/fixtures/*.json")
+func LoadFixtureFiles(db *database.Database, fsys fs.FS, pattern string, models ...database.Model) error {
+	filenames, err := fs.Glob(fsys, pattern)
+	if err != nil {
+		return fmt.Errorf("databasetest: invalid fixtures pattern: %s", err)
+	}
+	if len(filenames) == 0 {
+		return fmt.Errorf("databasetest: no fixtures match %s", pattern)
+	}
+
+	for _, filename := range filenames {
+		if err := loadFixtureFile(db, fsys, filename, models); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func loadFixtureFile(db *database.Database, fsys fs.FS, filename string, models []database.Model) error {
+	f, err := fsys.Open(filename)
+	if err != nil {
+		return fmt.Errorf("databasetest: cannot open fixture: %s", err)
+	}
+	defer f.Close()
+
+	load := LoadFixtures
+	if ext := path.Ext(filename); ext == ".yaml" || ext == ".yml" {
+		load = LoadYAMLFixtures
+	}
+	if err := load(db, f, models...); err != nil {
+		return fmt.Errorf("%s: %s", filename, err)
+	}
+
+	return nil
+}
+
+func expectDelim(decoder *json.Decoder, delim json.Delim) error {
+	tok, err := decoder.Token()
+	if err != nil {
+		return fmt.Errorf("databasetest: cannot read fixture: %s", err)
+	}
+	if tok != delim {
+		return fmt.Errorf("databasetest: invalid fixture, expected %s and got %v", delim, tok)
+	}
+
+	return nil
+}
+
+// fixtureInstance creates a new model and fills it with the values of the row.
+func fixtureInstance(c *database.Collection, model database.Model, row map[string]fixtureValue) (database.Model, error) {
+	instance := reflect.New(reflect.TypeOf(model).Elem()).Interface().(database.Model)
+	for key, decode := range row {
+		ptr, err := fields.Pointer(c, instance, key)
+		if err != nil {
+			return nil, err
+		}
+		if err := decode(ptr); err != nil {
+			return nil, fmt.Errorf("cannot decode column %s: %s", key, err)
+		}
+	}
+
+	return instance, nil
+}
@@ -0,0 +1,108 @@
+package databasetest
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/altipla-consulting/database"
+)
+
+func TestLoadFixtureFiles(t *testing.T) {
+	db := New(new(testingModel), new(testingAutoModel), new(testingHooker))
+
+	err := LoadFixtureFiles(db, os.DirFS("testdata"), "*.json", new(testingModel), new(testingAutoModel), new(testingHooker))
+	require.NoError(t, err)
+
+	var autos []*testingAutoModel
+	require.NoError(t, db.Collection(new(testingAutoModel)).Order("id").GetAll(&autos))
+	require.Len(t, autos, 2)
+	require.EqualValues(t, 5, autos[0].ID)
+	require.Equal(t, "foo", autos[0].Name)
+	require.EqualValues(t, 6, autos[1].ID)
+	require.Equal(t, "bar", autos[1].Name)
+
+	m := &testingModel{Code: "a"}
+	require.NoError(t, db.Collection(new(testingModel)).Get(m))
+	require.EqualValues(t, 30, m.Age)
+	require.Equal(t, time.Date(2019, time.January, 2, 3, 4, 5, 0, time.UTC), *m.Deleted)
+
+	hooker := &testingHooker{Code: "foo"}
+	require.NoError(t, db.Collection(new(testingHooker)).Get(hooker))
+	require.Equal(t, "changed", hooker.Changed)
+}
+
+func TestLoadFixtureFilesYAML(t *testing.T) {
+	db := New(new(testingModel), new(testingAutoModel), new(testingHooker))
+
+	err := LoadFixtureFiles(db, os.DirFS("testdata"), "*.yaml", new(testingModel), new(testingAutoModel), new(testingHooker))
+	require.NoError(t, err)
+
+	var autos []*testingAutoModel
+	require.NoError(t, db.Collection(new(testingAutoModel)).Order("id").GetAll(&autos))
+	require.Len(t, autos, 2)
+	require.EqualValues(t, 5, autos[0].ID)
+	require.Equal(t, "foo", autos[0].Name)
+	require.EqualValues(t, 6, autos[1].ID)
+	require.Equal(t, "bar", autos[1].Name)
+
+	m := &testingModel{Code: "a"}
+	require.NoError(t, db.Collection(new(testingModel)).Get(m))
+	require.EqualValues(t, 30, m.Age)
+	require.Equal(t, time.Date(2019, time.January, 2, 3, 4, 5, 0, time.UTC), *m.Deleted)
+
+	hooker := &testingHooker{Code: "foo"}
+	require.NoError(t, db.Collection(new(testingHooker)).Get(hooker))
+	require.Equal(t, "changed", hooker.Changed)
+}
+
+func TestLoadFixturesRegisteredModels(t *testing.T) {
+	db := New(new(testingCompositeModel))
+	database.RegisterModels(new(testingCompositeModel))
+
+	err := LoadFixtures(db, strings.NewReader(`{"testing_composite": [{"id": 1, "code": "a"}]}`))
+	require.NoError(t, err)
+
+	n, err := db.Collection(new(testingCompositeModel)).Count()
+	require.NoError(t, err)
+	require.EqualValues(t, 1, n)
+}
+
+func TestLoadFixturesErrors(t *testing.T) {
+	db := New(new(testingModel))
+
+	tests := []struct {
+		fixture, err string
+	}{
+		{`{"unknown": []}`, "databasetest: no model for table unknown in fixture"},
+		{`{"testing": [{"foo": 1}]}`, "databasetest: row 0 of table testing: database: unknown column foo in model *databasetest.testingModel"},
+		{`{"testing": [{"age": "foo"}]}`, "databasetest: row 0 of table testing: cannot decode column age: json: cannot unmarshal string into Go value of type int64"},
+		{`[]`, "databasetest: invalid fixture, expected { and got ["},
+	}
+	for _, test := range tests {
+		err := LoadFixtures(db, strings.NewReader(test.fixture), new(testingModel))
+		require.EqualError(t, err, test.err, test.fixture)
+	}
+}
+
+func TestLoadYAMLFixturesErrors(t *testing.T) {
+	db := New(new(testingModel))
+
+	tests := []struct {
+		fixture, err string
+	}{
+		{"unknown: []", "databasetest: no model for table unknown in fixture"},
+		{"testing:\n- foo: 1", "databasetest: row 0 of table testing: database: unknown column foo in model *databasetest.testingModel"},
+		{"testing:\n- age: foo", "databasetest: row 0 of table testing: cannot decode column age: yaml: unmarshal errors:\n  line 2: cannot unmarshal !!str `foo` into int64"},
+		{"- testing", "databasetest: invalid fixture, expected a map of tables in line 1"},
+	}
+	for _, test := range tests {
+		err := LoadYAMLFixtures(db, strings.NewReader(test.fixture), new(testingModel))
+		require.EqualError(t, err, test.err, test.fixture)
+	}
+
+	require.NoError(t, LoadYAMLFixtures(db, strings.NewReader(""), new(testingModel)))
+}
@@ -0,0 +1,117 @@
+package databasetest
+
+import (
+	"fmt"
+	"strings"
+)
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenIdent
+	tokenNumber
+	tokenString
+	tokenPlaceholder
+	tokenSymbol
+)
+
+type token struct {
+	kind tokenKind
+	text string
+
+	// Identifiers escaped with backticks are never keywords.
+	quoted bool
+}
+
+// is returns true if the token is the keyword or symbol, ignoring the case.
+func (tok token) is(text string) bool {
+	if tok.kind == tokenSymbol {
+		return tok.text == text
+	}
+
+	return tok.kind == tokenIdent && !tok.quoted && strings.EqualFold(tok.text, text)
+}
+
+var symbols = []string{"<=>", "<=", ">=", "<>", "!=", "=", "<", ">", "(", ")", ",", ".", "*", "+", "-", ";"}
+
+func tokenize(query string) ([]token, error) {
+	var tokens []token
+	for i := 0; i < len(query); {
+		ch := query[i]
+
+		switch {
+		case ch == ' ' || ch == '\t' || ch == '\n' || ch == '\r':
+			i++
+
+		case ch == '?':
+			tokens = append(tokens, token{kind: tokenPlaceholder, text: "?"})
+			i++
+
+		case ch == '`':
+			end := strings.IndexByte(query[i+1:], '`')
+			if end == -1 {
+				return nil, fmt.Errorf("databasetest: unterminated identifier in query: %s", query)
+			}
+			tokens = append(tokens, token{kind: tokenIdent, text: query[i+1 : i+1+end], quoted: true})
+			i += end + 2
+
+		case ch == '\'' || ch == '"':
+			var value strings.Builder
+			j := i + 1
+			for ; j < len(query); j++ {
+				if query[j] == '\\' && j+1 < len(query) {
+					j++
+					value.WriteByte(query[j])
+					continue
+				}
+				if query[j] == ch {
+					if j+1 < len(query) && query[j+1] == ch {
+						value.WriteByte(ch)
+						j++
+						continue
+					}
+					break
+				}
+				value.WriteByte(query[j])
+			}
+			if j >= len(query) {
+				return nil, fmt.Errorf("databasetest: unterminated string in query: %s", query)
+			}
+			tokens = append(tokens, token{kind: tokenString, text: value.String()})
+			i = j + 1
+
+		case ch >= '0' && ch <= '9':
+			j := i
+			for j < len(query) && (query[j] >= '0' && query[j] <= '9' || query[j] == '.') {
+				j++
+			}
+			tokens = append(tokens, token{kind: tokenNumber, text: query[i:j]})
+			i = j
+
+		case ch == '_' || ch >= 'a' && ch <= 'z' || ch >= 'A' && ch <= 'Z':
+			j := i
+			for j < len(query) && (query[j] == '_' || query[j] >= 'a' && query[j] <= 'z' || query[j] >= 'A' && query[j] <= 'Z' || query[j] >= '0' && query[j] <= '9') {
+				j++
+			}
+			tokens = append(tokens, token{kind: tokenIdent, text: query[i:j]})
+			i = j
+
+		default:
+			var found bool
+			for _, symbol := range symbols {
+				if strings.HasPrefix(query[i:], symbol) {
+					tokens = append(tokens, token{kind: tokenSymbol, text: symbol})
+					i += len(symbol)
+					found = true
+					break
+				}
+			}
+			if !found {
+				return nil, fmt.Errorf("databasetest: unexpected character %q in query: %s", ch, query)
+			}
+		}
+	}
+
+	return append(tokens, token{kind: tokenEOF}), nil
+}
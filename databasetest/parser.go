@@ -0,0 +1,901 @@
+package databasetest
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+type statement interface{}
+
+type createTableStmt struct {
+	table       string
+	ifNotExists bool
+	columns     []*column
+	pks         []string
+}
+
+type dropTableStmt struct {
+	table    string
+	ifExists bool
+}
+
+type alterAutoIncrementStmt struct {
+	table string
+	value int64
+}
+
+type insertStmt struct {
+	table   string
+	columns []string
+	rows    [][]expr
+}
+
+type updateStmt struct {
+	table string
+	alias string
+	sets  []assignment
+	where expr
+}
+
+type assignment struct {
+	column string
+	value  expr
+}
+
+type deleteStmt struct {
+	table string
+	alias string
+	where expr
+}
+
+type selectStmt struct {
+	cols   []selectCol
+	table  string
+	alias  string
+	where  expr
+	orders []orderBy
+
+	hasLimit      bool
+	limit, offset int64
+}
+
+type selectCol struct {
+	name string
+	expr expr
+
+	// Expands to every column of the table.
+	star bool
+
+	// COUNT(*) aggregates all the matching rows.
+	count bool
+}
+
+type orderBy struct {
+	expr expr
+	desc bool
+}
+
+type parser struct {
+	query  string
+	tokens []token
+	pos    int
+
+	// Number of placeholders found until now.
+	params int
+}
+
+func parse(query string) (statement, error) {
+	tokens, err := tokenize(query)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{query: query, tokens: tokens}
+
+	var stmt statement
+	switch {
+	case p.peek().is("CREATE"):
+		stmt, err = p.parseCreateTable()
+	case p.peek().is("DROP"):
+		stmt, err = p.parseDropTable()
+	case p.peek().is("ALTER"):
+		stmt, err = p.parseAlterTable()
+	case p.peek().is("INSERT"):
+		stmt, err = p.parseInsert()
+	case p.peek().is("UPDATE"):
+		stmt, err = p.parseUpdate()
+	case p.peek().is("DELETE"):
+		stmt, err = p.parseDelete()
+	case p.peek().is("SELECT"):
+		stmt, err = p.parseSelect()
+	default:
+		return nil, p.unsupported()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	p.accept(";")
+	if p.peek().kind != tokenEOF {
+		return nil, p.unsupported()
+	}
+
+	return stmt, nil
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) peekAt(n int) token {
+	if p.pos+n >= len(p.tokens) {
+		return p.tokens[len(p.tokens)-1]
+	}
+	return p.tokens[p.pos+n]
+}
+
+func (p *parser) next() token {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokenEOF {
+		p.pos++
+	}
+	return tok
+}
+
+// accept consumes the token if it is the keyword or symbol.
+func (p *parser) accept(text string) bool {
+	if p.peek().is(text) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *parser) expect(texts ...string) error {
+	for _, text := range texts {
+		if !p.accept(text) {
+			return p.unsupported()
+		}
+	}
+	return nil
+}
+
+func (p *parser) unsupported() error {
+	return fmt.Errorf("databasetest: unsupported query near %q: %s", p.peek().text, p.query)
+}
+
+// ident consumes an identifier. Keywords are accepted too as we do not keep
+// a list of reserved words; the grammar of each statement disambiguates them.
+func (p *parser) ident() (string, error) {
+	if p.peek().kind != tokenIdent {
+		return "", p.unsupported()
+	}
+	return p.next().text, nil
+}
+
+func (p *parser) identList() ([]string, error) {
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+	var names []string
+	for {
+		name, err := p.ident()
+		if err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+
+		if !p.accept(",") {
+			break
+		}
+	}
+	if err := p.expect(")"); err != nil {
+		return nil, err
+	}
+	return names, nil
+}
+
+// tableAlias consumes an optional alias after a table name.
+func (p *parser) tableAlias() (string, error) {
+	if p.accept("AS") {
+		return p.ident()
+	}
+	if tok := p.peek(); tok.kind == tokenIdent && (tok.quoted || !isKeyword(tok.text)) {
+		return p.next().text, nil
+	}
+	return "", nil
+}
+
+var keywords = []string{"WHERE", "ORDER", "LIMIT", "SET", "JOIN", "LEFT", "INNER", "GROUP", "HAVING", "ON"}
+
+func isKeyword(text string) bool {
+	for _, keyword := range keywords {
+		if strings.EqualFold(keyword, text) {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *parser) parseCreateTable() (statement, error) {
+	if err := p.expect("CREATE", "TABLE"); err != nil {
+		return nil, err
+	}
+	stmt := new(createTableStmt)
+	if p.accept("IF") {
+		if err := p.expect("NOT", "EXISTS"); err != nil {
+			return nil, err
+		}
+		stmt.ifNotExists = true
+	}
+
+	var err error
+	stmt.table, err = p.ident()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+	for {
+		switch {
+		case p.accept("PRIMARY"):
+			if err := p.expect("KEY"); err != nil {
+				return nil, err
+			}
+			if stmt.pks, err = p.identList(); err != nil {
+				return nil, err
+			}
+
+		case p.peek().is("KEY"), p.peek().is("INDEX"), p.peek().is("UNIQUE"), p.peek().is("CONSTRAINT"), p.peek().is("FOREIGN"), p.peek().is("FULLTEXT"):
+			p.skipDefinition()
+
+		default:
+			col, err := p.parseColumn()
+			if err != nil {
+				return nil, err
+			}
+			stmt.columns = append(stmt.columns, col)
+		}
+
+		if !p.accept(",") {
+			break
+		}
+	}
+	if err := p.expect(")"); err != nil {
+		return nil, err
+	}
+
+	// Table options like the engine or the charset do not matter.
+	for p.peek().kind != tokenEOF && !p.peek().is(";") {
+		p.next()
+	}
+
+	return stmt, nil
+}
+
+// parseColumn reads the definition of a column. The type is ignored, values
+// are stored as they are received from the driver.
+func (p *parser) parseColumn() (*column, error) {
+	name, err := p.ident()
+	if err != nil {
+		return nil, err
+	}
+	col := &column{name: name}
+
+	var depth int
+	for {
+		tok := p.peek()
+		switch {
+		case tok.kind == tokenEOF:
+			return nil, p.unsupported()
+		case depth == 0 && (tok.is(",") || tok.is(")")):
+			return col, nil
+		case tok.is("("):
+			depth++
+		case tok.is(")"):
+			depth--
+		case tok.is("AUTO_INCREMENT"):
+			col.auto = true
+		case tok.is("DEFAULT"):
+			p.next()
+			value, err := p.parsePrimary()
+			if err != nil {
+				return nil, err
+			}
+			lit, ok := value.(*literalExpr)
+			if !ok {
+				return nil, p.unsupported()
+			}
+			col.hasDefault = true
+			col.defaultValue = lit.value
+			continue
+		}
+		p.next()
+	}
+}
+
+// skipDefinition ignores an index or constraint of a CREATE TABLE.
+func (p *parser) skipDefinition() {
+	var depth int
+	for {
+		tok := p.peek()
+		switch {
+		case tok.kind == tokenEOF:
+			return
+		case depth == 0 && (tok.is(",") || tok.is(")")):
+			return
+		case tok.is("("):
+			depth++
+		case tok.is(")"):
+			depth--
+		}
+		p.next()
+	}
+}
+
+func (p *parser) parseDropTable() (statement, error) {
+	if err := p.expect("DROP", "TABLE"); err != nil {
+		return nil, err
+	}
+	stmt := new(dropTableStmt)
+	if p.accept("IF") {
+		if err := p.expect("EXISTS"); err != nil {
+			return nil, err
+		}
+		stmt.ifExists = true
+	}
+
+	var err error
+	stmt.table, err = p.ident()
+	return stmt, err
+}
+
+func (p *parser) parseAlterTable() (statement, error) {
+	if err := p.expect("ALTER", "TABLE"); err != nil {
+		return nil, err
+	}
+	table, err := p.ident()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expect("AUTO_INCREMENT"); err != nil {
+		return nil, err
+	}
+	p.accept("=")
+	if p.peek().kind != tokenNumber {
+		return nil, p.unsupported()
+	}
+	value, err := strconv.ParseInt(p.next().text, 10, 64)
+	if err != nil {
+		return nil, p.unsupported()
+	}
+
+	return &alterAutoIncrementStmt{table: table, value: value}, nil
+}
+
+func (p *parser) parseInsert() (statement, error) {
+	if err := p.expect("INSERT", "INTO"); err != nil {
+		return nil, err
+	}
+	table, err := p.ident()
+	if err != nil {
+		return nil, err
+	}
+	stmt := &insertStmt{table: table}
+	if stmt.columns, err = p.identList(); err != nil {
+		return nil, err
+	}
+
+	if !p.accept("VALUES") && !p.accept("VALUE") {
+		return nil, p.unsupported()
+	}
+	for {
+		if err := p.expect("("); err != nil {
+			return nil, err
+		}
+		var row []expr
+		for {
+			value, err := p.parseExpr()
+			if err != nil {
+				return nil, err
+			}
+			row = append(row, value)
+
+			if !p.accept(",") {
+				break
+			}
+		}
+		if err := p.expect(")"); err != nil {
+			return nil, err
+		}
+		if len(row) != len(stmt.columns) {
+			return nil, fmt.Errorf("databasetest: column count doesn't match value count: %s", p.query)
+		}
+		stmt.rows = append(stmt.rows, row)
+
+		if !p.accept(",") {
+			break
+		}
+	}
+
+	return stmt, nil
+}
+
+func (p *parser) parseUpdate() (statement, error) {
+	if err := p.expect("UPDATE"); err != nil {
+		return nil, err
+	}
+	table, err := p.ident()
+	if err != nil {
+		return nil, err
+	}
+	stmt := &updateStmt{table: table}
+	if stmt.alias, err = p.tableAlias(); err != nil {
+		return nil, err
+	}
+
+	if err := p.expect("SET"); err != nil {
+		return nil, err
+	}
+	for {
+		ref, err := p.parseColumnRef()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect("="); err != nil {
+			return nil, err
+		}
+		value, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		stmt.sets = append(stmt.sets, assignment{column: ref.name, value: value})
+
+		if !p.accept(",") {
+			break
+		}
+	}
+
+	if p.accept("WHERE") {
+		if stmt.where, err = p.parseExpr(); err != nil {
+			return nil, err
+		}
+	}
+
+	return stmt, nil
+}
+
+func (p *parser) parseDelete() (statement, error) {
+	if err := p.expect("DELETE", "FROM"); err != nil {
+		return nil, err
+	}
+	table, err := p.ident()
+	if err != nil {
+		return nil, err
+	}
+	stmt := &deleteStmt{table: table}
+	if stmt.alias, err = p.tableAlias(); err != nil {
+		return nil, err
+	}
+
+	if p.accept("WHERE") {
+		if stmt.where, err = p.parseExpr(); err != nil {
+			return nil, err
+		}
+	}
+
+	return stmt, nil
+}
+
+func (p *parser) parseSelect() (statement, error) {
+	if err := p.expect("SELECT"); err != nil {
+		return nil, err
+	}
+	stmt := new(selectStmt)
+	for {
+		col, err := p.parseSelectCol()
+		if err != nil {
+			return nil, err
+		}
+		stmt.cols = append(stmt.cols, col)
+
+		if !p.accept(",") {
+			break
+		}
+	}
+
+	if !p.accept("FROM") {
+		return stmt, nil
+	}
+	var err error
+	if stmt.table, err = p.ident(); err != nil {
+		return nil, err
+	}
+	if stmt.alias, err = p.tableAlias(); err != nil {
+		return nil, err
+	}
+
+	if p.accept("WHERE") {
+		if stmt.where, err = p.parseExpr(); err != nil {
+			return nil, err
+		}
+	}
+
+	if p.accept("ORDER") {
+		if err := p.expect("BY"); err != nil {
+			return nil, err
+		}
+		for {
+			value, err := p.parseExpr()
+			if err != nil {
+				return nil, err
+			}
+			order := orderBy{expr: value}
+			if p.accept("DESC") {
+				order.desc = true
+			} else {
+				p.accept("ASC")
+			}
+			stmt.orders = append(stmt.orders, order)
+
+			if !p.accept(",") {
+				break
+			}
+		}
+	}
+
+	if p.accept("LIMIT") {
+		stmt.hasLimit = true
+		first, err := p.parseInt()
+		if err != nil {
+			return nil, err
+		}
+		stmt.limit = first
+		if p.accept(",") {
+			stmt.offset = first
+			if stmt.limit, err = p.parseInt(); err != nil {
+				return nil, err
+			}
+		} else if p.accept("OFFSET") {
+			if stmt.offset, err = p.parseInt(); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return stmt, nil
+}
+
+func (p *parser) parseSelectCol() (selectCol, error) {
+	if p.accept("*") {
+		return selectCol{star: true}, nil
+	}
+
+	var col selectCol
+	if p.peek().is("COUNT") && p.peekAt(1).is("(") && p.peekAt(2).is("*") && p.peekAt(3).is(")") {
+		p.pos += 4
+		col = selectCol{name: "COUNT(*)", count: true}
+	} else {
+		start := p.pos
+		value, err := p.parseExpr()
+		if err != nil {
+			return selectCol{}, err
+		}
+		col = selectCol{expr: value}
+		if ref, ok := value.(*columnExpr); ok {
+			col.name = ref.name
+		} else {
+			var texts []string
+			for _, tok := range p.tokens[start:p.pos] {
+				texts = append(texts, tok.text)
+			}
+			col.name = strings.Join(texts, "")
+		}
+	}
+
+	if p.accept("AS") {
+		name, err := p.ident()
+		if err != nil {
+			return selectCol{}, err
+		}
+		col.name = name
+	}
+
+	return col, nil
+}
+
+func (p *parser) parseInt() (int64, error) {
+	if p.peek().kind != tokenNumber {
+		return 0, p.unsupported()
+	}
+	n, err := strconv.ParseInt(p.next().text, 10, 64)
+	if err != nil {
+		return 0, p.unsupported()
+	}
+	return n, nil
+}
+
+func (p *parser) parseColumnRef() (*columnExpr, error) {
+	name, err := p.ident()
+	if err != nil {
+		return nil, err
+	}
+	if !p.accept(".") {
+		return &columnExpr{name: name}, nil
+	}
+
+	qualified, err := p.ident()
+	if err != nil {
+		return nil, err
+	}
+	return &columnExpr{qualifier: name, name: qualified}, nil
+}
+
+func (p *parser) parseExpr() (expr, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("OR") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &logicExpr{op: "OR", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd() (expr, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("AND") {
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		left = &logicExpr{op: "AND", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseNot() (expr, error) {
+	if p.accept("NOT") {
+		value, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return &notExpr{value}, nil
+	}
+	return p.parsePredicate()
+}
+
+var comparisons = []string{"=", "!=", "<>", "<", "<=", ">", ">=", "<=>"}
+
+func (p *parser) parsePredicate() (expr, error) {
+	left, err := p.parseAdditive()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, op := range comparisons {
+		if p.accept(op) {
+			right, err := p.parseAdditive()
+			if err != nil {
+				return nil, err
+			}
+			return &compareExpr{op: op, left: left, right: right}, nil
+		}
+	}
+
+	if p.accept("IS") {
+		not := p.accept("NOT")
+		if err := p.expect("NULL"); err != nil {
+			return nil, err
+		}
+		return &isNullExpr{value: left, not: not}, nil
+	}
+
+	var not bool
+	if p.peek().is("NOT") && (p.peekAt(1).is("IN") || p.peekAt(1).is("LIKE") || p.peekAt(1).is("BETWEEN") || p.peekAt(1).is("REGEXP")) {
+		p.next()
+		not = true
+	}
+
+	switch {
+	case p.accept("IN"):
+		if err := p.expect("("); err != nil {
+			return nil, err
+		}
+		in := &inExpr{value: left, not: not}
+		if !p.accept("NULL") {
+			for {
+				item, err := p.parseExpr()
+				if err != nil {
+					return nil, err
+				}
+				in.list = append(in.list, item)
+
+				if !p.accept(",") {
+					break
+				}
+			}
+		}
+		if err := p.expect(")"); err != nil {
+			return nil, err
+		}
+		return in, nil
+
+	case p.accept("LIKE"):
+		pattern, err := p.parseAdditive()
+		if err != nil {
+			return nil, err
+		}
+		return &likeExpr{value: left, pattern: pattern, not: not}, nil
+
+	case p.accept("REGEXP"):
+		pattern, err := p.parseAdditive()
+		if err != nil {
+			return nil, err
+		}
+		return &regexpExpr{value: left, pattern: pattern, not: not}, nil
+
+	case p.accept("BETWEEN"):
+		from, err := p.parseAdditive()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect("AND"); err != nil {
+			return nil, err
+		}
+		to, err := p.parseAdditive()
+		if err != nil {
+			return nil, err
+		}
+		return &betweenExpr{value: left, from: from, to: to, not: not}, nil
+	}
+
+	return left, nil
+}
+
+func (p *parser) parseAdditive() (expr, error) {
+	left, err := p.parseCollate()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().is("+") || p.peek().is("-") {
+		op := p.next().text
+		right, err := p.parseCollate()
+		if err != nil {
+			return nil, err
+		}
+		left = &arithmeticExpr{op: op, left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseCollate() (expr, error) {
+	value, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+	if p.accept("COLLATE") {
+		collation, err := p.ident()
+		if err != nil {
+			return nil, err
+		}
+		return &collateExpr{value: value, collation: collation}, nil
+	}
+	return value, nil
+}
+
+func (p *parser) parsePrimary() (expr, error) {
+	tok := p.peek()
+	switch {
+	case tok.kind == tokenPlaceholder:
+		p.next()
+		p.params++
+		return &paramExpr{index: p.params - 1}, nil
+
+	case tok.kind == tokenNumber:
+		p.next()
+		if strings.Contains(tok.text, ".") {
+			f, err := strconv.ParseFloat(tok.text, 64)
+			if err != nil {
+				return nil, p.unsupported()
+			}
+			return &literalExpr{f}, nil
+		}
+		n, err := strconv.ParseInt(tok.text, 10, 64)
+		if err != nil {
+			return nil, p.unsupported()
+		}
+		return &literalExpr{n}, nil
+
+	case tok.kind == tokenString:
+		p.next()
+		return &literalExpr{tok.text}, nil
+
+	case tok.is("-"):
+		p.next()
+		value, err := p.parsePrimary()
+		if err != nil {
+			return nil, err
+		}
+		return &arithmeticExpr{op: "-", left: &literalExpr{int64(0)}, right: value}, nil
+
+	case tok.is("("):
+		p.next()
+		var items []expr
+		for {
+			item, err := p.parseExpr()
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, item)
+
+			if !p.accept(",") {
+				break
+			}
+		}
+		if err := p.expect(")"); err != nil {
+			return nil, err
+		}
+		if len(items) == 1 {
+			return items[0], nil
+		}
+		return &tupleExpr{items}, nil
+
+	case tok.is("NULL"):
+		p.next()
+		return &literalExpr{nil}, nil
+
+	case tok.is("TRUE"):
+		p.next()
+		return &literalExpr{int64(1)}, nil
+
+	case tok.is("FALSE"):
+		p.next()
+		return &literalExpr{int64(0)}, nil
+
+	case tok.kind == tokenIdent:
+		if !tok.quoted && p.peekAt(1).is("(") {
+			return p.parseCall()
+		}
+		return p.parseColumnRef()
+	}
+
+	return nil, p.unsupported()
+}
+
+func (p *parser) parseCall() (expr, error) {
+	call := &callExpr{name: strings.ToUpper(p.next().text)}
+	if _, ok := functions[call.name]; !ok {
+		p.pos--
+		return nil, p.unsupported()
+	}
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+	if !p.accept(")") {
+		for {
+			arg, err := p.parseExpr()
+			if err != nil {
+				return nil, err
+			}
+			call.args = append(call.args, arg)
+
+			if !p.accept(",") {
+				break
+			}
+		}
+		if err := p.expect(")"); err != nil {
+			return nil, err
+		}
+	}
+
+	return call, nil
+}
@@ -0,0 +1,482 @@
+package databasetest
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+type column struct {
+	name string
+	auto bool
+
+	hasDefault   bool
+	defaultValue interface{}
+}
+
+type table struct {
+	name    string
+	columns []*column
+	index   map[string]int
+	pks     []int
+
+	rows          [][]interface{}
+	autoIncrement int64
+}
+
+func (t *table) clone() *table {
+	cloned := *t
+	cloned.rows = make([][]interface{}, len(t.rows))
+	for i, row := range t.rows {
+		cloned.rows[i] = append([]interface{}(nil), row...)
+	}
+	return &cloned
+}
+
+// key returns the primary key of the row to detect duplicates.
+func (t *table) key(row []interface{}) string {
+	parts := make([]string, len(t.pks))
+	for i, idx := range t.pks {
+		parts[i] = toString(row[idx])
+	}
+	return strings.Join(parts, "-")
+}
+
+// store keeps the tables of a fake database. Statements are serialized with
+// a lock, so they are atomic but transactions are not isolated from each other.
+type store struct {
+	mu     sync.Mutex
+	tables map[string]*table
+
+	parsed sync.Map
+}
+
+func newStore() *store {
+	return &store{tables: map[string]*table{}}
+}
+
+func (st *store) snapshot() map[string]*table {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	tables := make(map[string]*table, len(st.tables))
+	for name, t := range st.tables {
+		tables[name] = t.clone()
+	}
+	return tables
+}
+
+func (st *store) restore(tables map[string]*table) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	st.tables = tables
+}
+
+// parse reads the statement of a query, caching the result as tests repeat
+// the same queries a lot.
+func (st *store) parse(query string) (statement, error) {
+	if stmt, ok := st.parsed.Load(query); ok {
+		return stmt, nil
+	}
+	stmt, err := parse(query)
+	if err != nil {
+		return nil, err
+	}
+	st.parsed.Store(query, stmt)
+	return stmt, nil
+}
+
+type result struct {
+	lastInsertID int64
+	rowsAffected int64
+}
+
+func (r *result) LastInsertId() (int64, error) {
+	return r.lastInsertID, nil
+}
+
+func (r *result) RowsAffected() (int64, error) {
+	return r.rowsAffected, nil
+}
+
+func (st *store) exec(query string, args []interface{}) (driver.Result, error) {
+	stmt, err := st.parse(query)
+	if err != nil {
+		return nil, err
+	}
+
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	switch stmt := stmt.(type) {
+	case *createTableStmt:
+		return st.createTable(stmt)
+	case *dropTableStmt:
+		return st.dropTable(stmt)
+	case *alterAutoIncrementStmt:
+		return st.alterAutoIncrement(stmt)
+	case *insertStmt:
+		return st.insert(stmt, args)
+	case *updateStmt:
+		return st.update(stmt, args)
+	case *deleteStmt:
+		return st.delete(stmt, args)
+	case *selectStmt:
+		if _, _, err := st.selectRows(stmt, args); err != nil {
+			return nil, err
+		}
+		return new(result), nil
+	}
+	panic("should not reach here")
+}
+
+func (st *store) query(query string, args []interface{}) ([]string, [][]interface{}, error) {
+	stmt, err := st.parse(query)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	sel, ok := stmt.(*selectStmt)
+	if !ok {
+		return nil, nil, fmt.Errorf("databasetest: only SELECT statements return rows: %s", query)
+	}
+	return st.selectRows(sel, args)
+}
+
+func (st *store) table(name string) (*table, error) {
+	t, ok := st.tables[name]
+	if !ok {
+		return nil, fmt.Errorf("databasetest: table %s doesn't exist", name)
+	}
+	return t, nil
+}
+
+func (st *store) createTable(stmt *createTableStmt) (driver.Result, error) {
+	if _, ok := st.tables[stmt.table]; ok {
+		if stmt.ifNotExists {
+			return new(result), nil
+		}
+		return nil, fmt.Errorf("databasetest: table %s already exists", stmt.table)
+	}
+
+	t := &table{
+		name:    stmt.table,
+		columns: stmt.columns,
+		index:   map[string]int{},
+	}
+	for i, col := range stmt.columns {
+		t.index[col.name] = i
+	}
+	for _, pk := range stmt.pks {
+		idx, ok := t.index[pk]
+		if !ok {
+			return nil, fmt.Errorf("databasetest: unknown primary key column %s in table %s", pk, stmt.table)
+		}
+		t.pks = append(t.pks, idx)
+	}
+	st.tables[stmt.table] = t
+
+	return new(result), nil
+}
+
+func (st *store) dropTable(stmt *dropTableStmt) (driver.Result, error) {
+	if _, ok := st.tables[stmt.table]; !ok && !stmt.ifExists {
+		return nil, fmt.Errorf("databasetest: table %s doesn't exist", stmt.table)
+	}
+	delete(st.tables, stmt.table)
+
+	return new(result), nil
+}
+
+func (st *store) alterAutoIncrement(stmt *alterAutoIncrementStmt) (driver.Result, error) {
+	t, err := st.table(stmt.table)
+	if err != nil {
+		return nil, err
+	}
+
+	// Like MySQL the counter cannot go below the maximum value already stored.
+	t.autoIncrement = stmt.value - 1
+	for _, col := range t.columns {
+		if !col.auto {
+			continue
+		}
+		for _, row := range t.rows {
+			if n, ok := toNumber(row[t.index[col.name]]).(int64); ok && n > t.autoIncrement {
+				t.autoIncrement = n
+			}
+		}
+	}
+
+	return new(result), nil
+}
+
+func (st *store) insert(stmt *insertStmt, args []interface{}) (driver.Result, error) {
+	t, err := st.table(stmt.table)
+	if err != nil {
+		return nil, err
+	}
+	indexes := make([]int, len(stmt.columns))
+	for i, name := range stmt.columns {
+		idx, ok := t.index[name]
+		if !ok {
+			return nil, fmt.Errorf("databasetest: unknown column %s in table %s", name, t.name)
+		}
+		indexes[i] = idx
+	}
+
+	keys := map[string]bool{}
+	if len(t.pks) > 0 {
+		for _, row := range t.rows {
+			keys[t.key(row)] = true
+		}
+	}
+
+	res := new(result)
+	var inserted [][]interface{}
+	s := &scope{args: args}
+	for _, values := range stmt.rows {
+		row := make([]interface{}, len(t.columns))
+		for i, col := range t.columns {
+			if col.hasDefault {
+				row[i] = col.defaultValue
+			}
+		}
+		for i, value := range values {
+			if row[indexes[i]], err = value.eval(s); err != nil {
+				return nil, err
+			}
+		}
+
+		for i, col := range t.columns {
+			if !col.auto {
+				continue
+			}
+			if n, ok := toNumber(row[i]).(int64); row[i] == nil || ok && n == 0 {
+				t.autoIncrement++
+				row[i] = t.autoIncrement
+				res.lastInsertID = t.autoIncrement
+			} else if ok && n > t.autoIncrement {
+				t.autoIncrement = n
+			}
+		}
+
+		if len(t.pks) > 0 {
+			key := t.key(row)
+			if keys[key] {
+				return nil, fmt.Errorf("databasetest: duplicate entry '%s' for key PRIMARY in table %s", key, t.name)
+			}
+			keys[key] = true
+		}
+
+		inserted = append(inserted, row)
+	}
+	t.rows = append(t.rows, inserted...)
+	res.rowsAffected = int64(len(inserted))
+
+	return res, nil
+}
+
+func (st *store) update(stmt *updateStmt, args []interface{}) (driver.Result, error) {
+	t, err := st.table(stmt.table)
+	if err != nil {
+		return nil, err
+	}
+	indexes := make([]int, len(stmt.sets))
+	for i, set := range stmt.sets {
+		idx, ok := t.index[set.column]
+		if !ok {
+			return nil, fmt.Errorf("databasetest: unknown column %s in table %s", set.column, t.name)
+		}
+		indexes[i] = idx
+	}
+
+	// Rows are updated in a copy to leave the table untouched if any of them fails.
+	rows := make([][]interface{}, len(t.rows))
+	copy(rows, t.rows)
+
+	res := new(result)
+	keys := map[string]int{}
+	for i, row := range rows {
+		s := &scope{table: t, alias: stmt.alias, row: row, args: args}
+		ok, err := matches(stmt.where, s)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			updated := append([]interface{}(nil), row...)
+			for j, set := range stmt.sets {
+				if updated[indexes[j]], err = set.value.eval(s); err != nil {
+					return nil, err
+				}
+			}
+			rows[i] = updated
+			res.rowsAffected++
+		}
+
+		if len(t.pks) > 0 {
+			key := t.key(rows[i])
+			if _, ok := keys[key]; ok {
+				return nil, fmt.Errorf("databasetest: duplicate entry '%s' for key PRIMARY in table %s", key, t.name)
+			}
+			keys[key] = i
+		}
+	}
+	t.rows = rows
+
+	return res, nil
+}
+
+func (st *store) delete(stmt *deleteStmt, args []interface{}) (driver.Result, error) {
+	t, err := st.table(stmt.table)
+	if err != nil {
+		return nil, err
+	}
+
+	res := new(result)
+	var kept [][]interface{}
+	for _, row := range t.rows {
+		ok, err := matches(stmt.where, &scope{table: t, alias: stmt.alias, row: row, args: args})
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			res.rowsAffected++
+			continue
+		}
+		kept = append(kept, row)
+	}
+	t.rows = kept
+
+	return res, nil
+}
+
+func (st *store) selectRows(stmt *selectStmt, args []interface{}) ([]string, [][]interface{}, error) {
+	var t *table
+	rows := [][]interface{}{nil}
+	if stmt.table != "" {
+		var err error
+		if t, err = st.table(stmt.table); err != nil {
+			return nil, nil, err
+		}
+		rows = t.rows
+	}
+
+	var columns []string
+	var exprs []expr
+	var count bool
+	for _, col := range stmt.cols {
+		switch {
+		case col.star:
+			if t == nil {
+				return nil, nil, fmt.Errorf("databasetest: SELECT * requires a table")
+			}
+			for _, c := range t.columns {
+				columns = append(columns, c.name)
+				exprs = append(exprs, &columnExpr{name: c.name})
+			}
+		case col.count:
+			if len(stmt.cols) > 1 {
+				return nil, nil, fmt.Errorf("databasetest: COUNT(*) cannot be combined with other columns")
+			}
+			columns = append(columns, col.name)
+			count = true
+		default:
+			columns = append(columns, col.name)
+			exprs = append(exprs, col.expr)
+		}
+	}
+
+	var matched [][]interface{}
+	for _, row := range rows {
+		ok, err := matches(stmt.where, &scope{table: t, alias: stmt.alias, row: row, args: args})
+		if err != nil {
+			return nil, nil, err
+		}
+		if ok {
+			matched = append(matched, row)
+		}
+	}
+
+	if count {
+		return columns, [][]interface{}{{int64(len(matched))}}, nil
+	}
+
+	if len(stmt.orders) > 0 {
+		keys := make([][]interface{}, len(matched))
+		for i, row := range matched {
+			keys[i] = make([]interface{}, len(stmt.orders))
+			for j, order := range stmt.orders {
+				var err error
+				if keys[i][j], err = order.expr.eval(&scope{table: t, alias: stmt.alias, row: row, args: args}); err != nil {
+					return nil, nil, err
+				}
+			}
+		}
+		perm := make([]int, len(matched))
+		for i := range perm {
+			perm[i] = i
+		}
+		sort.SliceStable(perm, func(a, b int) bool {
+			for j, order := range stmt.orders {
+				cmp := compareNulls(keys[perm[a]][j], keys[perm[b]][j])
+				if order.desc {
+					cmp = -cmp
+				}
+				if cmp != 0 {
+					return cmp < 0
+				}
+			}
+			return false
+		})
+		sorted := make([][]interface{}, len(matched))
+		for i, idx := range perm {
+			sorted[i] = matched[idx]
+		}
+		matched = sorted
+	}
+
+	if stmt.hasLimit {
+		if stmt.offset >= int64(len(matched)) {
+			matched = nil
+		} else {
+			matched = matched[stmt.offset:]
+		}
+		if stmt.limit < int64(len(matched)) {
+			matched = matched[:stmt.limit]
+		}
+	}
+
+	results := make([][]interface{}, len(matched))
+	for i, row := range matched {
+		results[i] = make([]interface{}, len(exprs))
+		s := &scope{table: t, alias: stmt.alias, row: row, args: args}
+		for j, e := range exprs {
+			var err error
+			if results[i][j], err = e.eval(s); err != nil {
+				return nil, nil, err
+			}
+		}
+	}
+
+	return columns, results, nil
+}
+
+// compareNulls orders the values sorting NULL before any other value like MySQL.
+func compareNulls(a, b interface{}) int {
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return -1
+	case b == nil:
+		return 1
+	}
+	cmp, _ := compare(a, b)
+	return cmp
+}
@@ -0,0 +1,239 @@
+package databasetest
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/altipla-consulting/database"
+)
+
+type testConfig struct {
+	credentials database.Credentials
+	models      []database.Model
+	schema      bool
+	options     []database.Option
+}
+
+// TestOption configures the database opened with WithTestDB.
+type TestOption func(cnf *testConfig)
+
+// WithCredentials connects to the server with the credentials instead of reading
+// them from the environment.
+func WithCredentials(credentials database.Credentials) TestOption {
+	return func(cnf *testConfig) {
+		cnf.credentials = credentials
+	}
+}
+
+// WithModels creates the tables of the models before the test if they do not
+// exist yet. See Collection.CreateTableSQL for the generated schema.
+func WithModels(models ...database.Model) TestOption {
+	return func(cnf *testConfig) {
+		cnf.models = append(cnf.models, models...)
+	}
+}
+
+// WithSchema runs the test in a throwaway database created for it and dropped
+// when it finishes, instead of a transaction in the shared one. Use it for tests
+// that run in parallel writing the same rows, or that run statements that commit
+// the transaction implicitly, like schema changes or Truncate. The user needs
+// privileges to create and drop databases.
+func WithSchema() TestOption {
+	return func(cnf *testConfig) {
+		cnf.schema = true
+	}
+}
+
+// WithOptions passes options to configure the opened database, like WithDebug.
+func WithOptions(options ...database.Option) TestOption {
+	return func(cnf *testConfig) {
+		cnf.options = append(cnf.options, options...)
+	}
+}
+
+// WithTestDB opens a connection to a MySQL server for the test and isolates the
+// changes it makes. By default every query runs inside a transaction that is
+// rolled back when the test finishes, so tests do not see the changes of each
+// other and the tables stay as they were:
+//
+//	func TestCreateHotel(t *testing.T) {
+//		db := databasetest.WithTestDB(t, databasetest.WithModels(new(models.Hotel)))
+//
+//		require.NoError(t, CreateHotel(db, "Hotel Foo"))
+//		...
+//	}
+//
+// The transaction needs a single connection, so an Iterator or Stream should be
+// closed before running other queries. Schema changes and Truncate commit the
+// transaction implicitly in MySQL; use WithSchema for those tests.
+//
+// The credentials are read from the same environment variables of dbctl:
+// DATABASE_USER, DATABASE_PASSWORD, DATABASE_ADDRESS (localhost:3306 by default),
+// DATABASE_NAME, DATABASE_CHARSET, DATABASE_COLLATION and DATABASE_PROTOCOL.
+// The test fails if it cannot connect to the server.
+func WithTestDB(t testing.TB, opts ...TestOption) *database.Database {
+	t.Helper()
+
+	cnf := &testConfig{credentials: envCredentials()}
+	for _, opt := range opts {
+		opt(cnf)
+	}
+
+	if cnf.schema {
+		return openSchema(t, cnf)
+	}
+
+	return openTransaction(t, cnf)
+}
+
+func envCredentials() database.Credentials {
+	address := os.Getenv("DATABASE_ADDRESS")
+	if address == "" {
+		address = "localhost:3306"
+	}
+
+	return database.Credentials{
+		User:      os.Getenv("DATABASE_USER"),
+		Password:  os.Getenv("DATABASE_PASSWORD"),
+		Address:   address,
+		Database:  os.Getenv("DATABASE_NAME"),
+		Charset:   os.Getenv("DATABASE_CHARSET"),
+		Collation: os.Getenv("DATABASE_COLLATION"),
+		Protocol:  os.Getenv("DATABASE_PROTOCOL"),
+	}
+}
+
+// openTransaction pins a single connection to the server and starts a transaction
+// in it. Every query of the database runs in that session until the test finishes.
+func openTransaction(t testing.TB, cnf *testConfig) *database.Database {
+	t.Helper()
+
+	// Open the connection with the driver directly to keep it out of any pool.
+	dsn := database.MySQL.DSN(cnf.credentials)
+	pool, err := sql.Open(database.MySQL.DriverName(), dsn)
+	if err != nil {
+		t.Fatalf("databasetest: cannot connect to mysql: %s", err)
+	}
+	drv := pool.Driver()
+	pool.Close()
+	conn, err := drv.Open(dsn)
+	if err != nil {
+		t.Fatalf("databasetest: cannot connect to mysql: %s", err)
+	}
+	t.Cleanup(func() {
+		conn.Close()
+	})
+
+	sess := sql.OpenDB(&pinnedConnector{conn: conn, driver: drv})
+	t.Cleanup(func() {
+		sess.Close()
+	})
+	sess.SetMaxOpenConns(1)
+
+	db := database.FromDB(sess, cnf.options...)
+	createTables(t, db, cnf.models)
+
+	if err := db.Exec(`START TRANSACTION`); err != nil {
+		t.Fatalf("databasetest: cannot start transaction: %s", err)
+	}
+	t.Cleanup(func() {
+		if err := db.Exec(`ROLLBACK`); err != nil {
+			t.Errorf("databasetest: cannot rollback transaction: %s", err)
+		}
+	})
+
+	return db
+}
+
+// pinnedConnector always returns the same connection to the pool. If the pool
+// discards it after an error the queries keep failing, instead of running in a
+// new connection outside the transaction of the test.
+type pinnedConnector struct {
+	conn   driver.Conn
+	driver driver.Driver
+}
+
+func (c *pinnedConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	return pinnedConn{c.conn}, nil
+}
+
+func (c *pinnedConnector) Driver() driver.Driver {
+	return c.driver
+}
+
+// pinnedConn ignores the pool when it closes the connection; openTransaction
+// closes it when the test finishes.
+type pinnedConn struct {
+	driver.Conn
+}
+
+func (conn pinnedConn) Close() error {
+	return nil
+}
+
+// openSchema creates a new database with a random name for the test.
+func openSchema(t testing.TB, cnf *testConfig) *database.Database {
+	t.Helper()
+
+	admin, err := database.Open(cnf.credentials, cnf.options...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(admin.Close)
+
+	name, err := schemaName()
+	if err != nil {
+		t.Fatalf("databasetest: cannot generate schema name: %s", err)
+	}
+	if err := admin.Exec(fmt.Sprintf("CREATE DATABASE `%s`", name)); err != nil {
+		t.Fatalf("databasetest: cannot create schema %s: %s", name, err)
+	}
+	t.Cleanup(func() {
+		if err := admin.Exec(fmt.Sprintf("DROP DATABASE `%s`", name)); err != nil {
+			t.Errorf("databasetest: cannot drop schema %s: %s", name, err)
+		}
+	})
+
+	credentials := cnf.credentials
+	credentials.Database = name
+	db, err := database.Open(credentials, cnf.options...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(db.Close)
+
+	createTables(t, db, cnf.models)
+
+	return db
+}
+
+func schemaName() (string, error) {
+	suffix := make([]byte, 8)
+	if _, err := rand.Read(suffix); err != nil {
+		return "", err
+	}
+
+	return "test_" + hex.EncodeToString(suffix), nil
+}
+
+func createTables(t testing.TB, db *database.Database, models []database.Model) {
+	t.Helper()
+
+	for _, model := range models {
+		statement, err := db.Collection(model).CreateTableSQL()
+		if err != nil {
+			t.Fatalf("databasetest: cannot create the table of %T: %s", model, err)
+		}
+		statement = strings.Replace(statement, "CREATE TABLE ", "CREATE TABLE IF NOT EXISTS ", 1)
+		if err := db.Exec(statement); err != nil {
+			t.Fatalf("databasetest: cannot create the table of %T: %s", model, err)
+		}
+	}
+}
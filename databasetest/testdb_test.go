@@ -0,0 +1,108 @@
+package databasetest
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/altipla-consulting/database"
+)
+
+var testCredentials = database.Credentials{
+	User:      "dev-user",
+	Password:  "dev-password",
+	Address:   "localhost:3307",
+	Database:  "test",
+	Charset:   "utf8mb4",
+	Collation: "utf8mb4_bin",
+}
+
+func skipSQLite(t *testing.T) {
+	if os.Getenv("TEST_DIALECT") == "sqlite" {
+		t.Skip("test requires a MySQL database")
+	}
+}
+
+type testingIsolatedModel struct {
+	database.ModelTracking
+
+	Code string `db:"code,pk"`
+	Name string `db:"name"`
+}
+
+func (model *testingIsolatedModel) TableName() string {
+	return "databasetest_isolated"
+}
+
+func TestWithTestDBRollback(t *testing.T) {
+	skipSQLite(t)
+
+	for i := 0; i < 2; i++ {
+		t.Run("insert", func(t *testing.T) {
+			db := WithTestDB(t, WithCredentials(testCredentials), WithModels(new(testingIsolatedModel)))
+			isolated := db.Collection(new(testingIsolatedModel))
+
+			require.NoError(t, isolated.Put(&testingIsolatedModel{Code: "foo", Name: "bar"}))
+
+			n, err := isolated.Count()
+			require.NoError(t, err)
+			require.EqualValues(t, 1, n)
+		})
+	}
+}
+
+func TestWithTestDBSchema(t *testing.T) {
+	skipSQLite(t)
+
+	credentials := testCredentials
+	credentials.User = "root"
+	credentials.Password = "dev-root"
+
+	var name string
+	t.Run("schema", func(t *testing.T) {
+		db := WithTestDB(t, WithCredentials(credentials), WithSchema(), WithModels(new(testingIsolatedModel)))
+		require.NoError(t, db.QueryRow(`SELECT DATABASE()`).Scan(&name))
+		require.NotEqual(t, "test", name)
+
+		isolated := db.Collection(new(testingIsolatedModel))
+		require.NoError(t, isolated.Put(&testingIsolatedModel{Code: "foo"}))
+		require.NoError(t, isolated.Truncate())
+	})
+
+	admin, err := database.Open(credentials)
+	require.NoError(t, err)
+	defer admin.Close()
+
+	var n int64
+	require.NoError(t, admin.QueryRow(`SELECT COUNT(*) FROM information_schema.schemata WHERE schema_name = ?`, name).Scan(&n))
+	require.Zero(t, n)
+}
+
+type closeCounterConn struct {
+	driver.Conn
+	closed int
+}
+
+func (conn *closeCounterConn) Close() error {
+	conn.closed++
+	return nil
+}
+
+func TestPinnedConnector(t *testing.T) {
+	pinned := &closeCounterConn{Conn: &conn{store: newStore()}}
+	sess := sql.OpenDB(&pinnedConnector{conn: pinned, driver: fakeDriver{}})
+	sess.SetMaxIdleConns(0)
+
+	db := database.FromDB(sess)
+	require.NoError(t, db.CreateTable(new(testingModel)))
+	require.NoError(t, db.Collection(new(testingModel)).Put(&testingModel{Code: "foo"}))
+	n, err := db.Collection(new(testingModel)).Count()
+	require.NoError(t, err)
+	require.EqualValues(t, 1, n)
+
+	require.NoError(t, sess.Close())
+	require.Zero(t, pinned.closed)
+}
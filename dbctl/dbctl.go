@@ -0,0 +1,303 @@
+// Package dbctl implements a command line tool to run migrations and inspect
+// the schema of the database.
+//
+// The stock binary in cmd/dbctl can run migrations and dump the schema. To
+// verify the schema against the models of an application build your own binary
+// importing the package that registers them with database.RegisterModels:
+//
+//	package main
+//
+//	import (
+//		"github.com/altipla-consulting/database/dbctl"
+//
+//		_ "example.com/myapp/models"
+//	)
+//
+//	func main() {
+//		dbctl.Main()
+//	}
+package dbctl
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/altipla-consulting/database"
+	"github.com/altipla-consulting/database/migrations"
+)
+
+const usage = `Usage: dbctl [flags] <command> [arguments]
+
+Commands:
+  migrate up              Apply every pending migration.
+  migrate down [n]        Revert the last n migrations (1 by default).
+  migrate status          Show the state of every migration.
+  migrate create <name>   Create empty up and down files for a new migration.
+  schema verify           Check the registered models against the database.
+  schema dump             Print the CREATE statement of every table and view.
+
+Flags:
+`
+
+var nameRe = regexp.MustCompile(`^[a-zA-Z0-9_\-]+$`)
+
+// errUsage is returned when the command line arguments are not valid.
+var errUsage = errors.New("dbctl: invalid arguments")
+
+type config struct {
+	credentials database.Credentials
+	dir         string
+	table       string
+	dryRun      bool
+	lockTimeout time.Duration
+}
+
+// Main runs the command line tool with the arguments of the process and exits
+// when finished.
+func Main() {
+	if err := Run(os.Args[1:], os.Stdout); err != nil {
+		if err != errUsage {
+			fmt.Fprintln(os.Stderr, err)
+		}
+		os.Exit(1)
+	}
+}
+
+// Run executes the command line tool with the provided arguments, writing its
+// output to w.
+func Run(args []string, w io.Writer) error {
+	cnf := new(config)
+
+	fs := flag.NewFlagSet("dbctl", flag.ContinueOnError)
+	fs.SetOutput(w)
+	fs.Usage = func() {
+		fmt.Fprint(w, usage)
+		fs.PrintDefaults()
+	}
+	fs.StringVar(&cnf.credentials.User, "user", os.Getenv("DATABASE_USER"), "User of the database. Env: DATABASE_USER.")
+	fs.StringVar(&cnf.credentials.Password, "password", os.Getenv("DATABASE_PASSWORD"), "Password of the user. Env: DATABASE_PASSWORD.")
+	fs.StringVar(&cnf.credentials.Address, "address", envDefault("DATABASE_ADDRESS", "localhost:3306"), "Address of the database server. Env: DATABASE_ADDRESS.")
+	fs.StringVar(&cnf.credentials.Database, "database", os.Getenv("DATABASE_NAME"), "Name of the database. Env: DATABASE_NAME.")
+	fs.StringVar(&cnf.credentials.Charset, "charset", os.Getenv("DATABASE_CHARSET"), "Charset of the connection. Env: DATABASE_CHARSET.")
+	fs.StringVar(&cnf.credentials.Collation, "collation", os.Getenv("DATABASE_COLLATION"), "Collation of the connection. Env: DATABASE_COLLATION.")
+	fs.StringVar(&cnf.credentials.Protocol, "protocol", os.Getenv("DATABASE_PROTOCOL"), "Protocol of the connection. Env: DATABASE_PROTOCOL.")
+	fs.StringVar(&cnf.dir, "dir", envDefault("DATABASE_MIGRATIONS", "migrations"), "Directory with the migration files. Env: DATABASE_MIGRATIONS.")
+	fs.StringVar(&cnf.table, "table", "schema_migrations", "Bookkeeping table of the migrations.")
+	fs.BoolVar(&cnf.dryRun, "dry-run", false, "Print the statements of the migrations instead of running them.")
+	fs.DurationVar(&cnf.lockTimeout, "lock-timeout", time.Minute, "Maximum time to wait for other replicas running migrations.")
+	if err := fs.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return nil
+		}
+		return errUsage
+	}
+
+	ctx := context.Background()
+	args = fs.Args()
+	if len(args) < 2 {
+		fs.Usage()
+		return errUsage
+	}
+
+	switch args[0] + " " + args[1] {
+	case "migrate create":
+		if len(args) != 3 || !nameRe.MatchString(args[2]) {
+			fs.Usage()
+			return errUsage
+		}
+		return migrateCreate(w, cnf.dir, args[2], time.Now())
+
+	case "migrate up":
+		return withMigrator(cnf, w, func(m *migrations.Migrator) error {
+			return m.Up(ctx)
+		})
+
+	case "migrate down":
+		n := 1
+		if len(args) > 2 {
+			var err error
+			n, err = strconv.Atoi(args[2])
+			if err != nil || n <= 0 {
+				fs.Usage()
+				return errUsage
+			}
+		}
+		return withMigrator(cnf, w, func(m *migrations.Migrator) error {
+			return m.Down(ctx, n)
+		})
+
+	case "migrate status":
+		return withMigrator(cnf, w, func(m *migrations.Migrator) error {
+			return migrateStatus(ctx, w, m)
+		})
+
+	case "schema verify":
+		return withDatabase(cnf, func(db *database.Database) error {
+			return schemaVerify(w, db, database.RegisteredModels())
+		})
+
+	case "schema dump":
+		return withDatabase(cnf, func(db *database.Database) error {
+			return schemaDump(ctx, w, db)
+		})
+	}
+
+	fs.Usage()
+	return errUsage
+}
+
+func envDefault(name, value string) string {
+	if env := os.Getenv(name); env != "" {
+		return env
+	}
+
+	return value
+}
+
+func withDatabase(cnf *config, fn func(db *database.Database) error) error {
+	db, err := database.Open(cnf.credentials)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	return fn(db)
+}
+
+func withMigrator(cnf *config, w io.Writer, fn func(m *migrations.Migrator) error) error {
+	list, err := migrations.FromDir(cnf.dir)
+	if err != nil {
+		return err
+	}
+
+	options := []migrations.Option{
+		migrations.WithTable(cnf.table),
+		migrations.WithLockTimeout(cnf.lockTimeout),
+	}
+	if cnf.dryRun {
+		options = append(options, migrations.WithDryRun(w))
+	}
+
+	m, err := migrations.Open(cnf.credentials, list, options...)
+	if err != nil {
+		return err
+	}
+	defer m.Close()
+
+	return fn(m)
+}
+
+func migrateCreate(w io.Writer, dir, name string, now time.Time) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	base := fmt.Sprintf("%s_%s", now.UTC().Format("20060102150405"), name)
+	for _, direction := range []string{"up", "down"} {
+		filename := filepath.Join(dir, fmt.Sprintf("%s.%s.sql", base, direction))
+		if err := os.WriteFile(filename, nil, 0644); err != nil {
+			return err
+		}
+		fmt.Fprintln(w, "created", filename)
+	}
+
+	return nil
+}
+
+func migrateStatus(ctx context.Context, w io.Writer, m *migrations.Migrator) error {
+	statuses, err := m.Status(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, status := range statuses {
+		switch {
+		case status.Unknown:
+			fmt.Fprintf(w, "%s\tapplied %s\tunknown migration\n", status.Migration, status.AppliedAt.Format(time.RFC3339))
+		case status.Applied:
+			fmt.Fprintf(w, "%s\tapplied %s\n", status.Migration, status.AppliedAt.Format(time.RFC3339))
+		default:
+			fmt.Fprintf(w, "%s\tpending\n", status.Migration)
+		}
+	}
+
+	return nil
+}
+
+func schemaVerify(w io.Writer, db *database.Database, models []database.Model) error {
+	if len(models) == 0 {
+		return fmt.Errorf("dbctl: no registered models, build your own binary importing the models package")
+	}
+
+	err := db.VerifySchema(models...)
+	if serr, ok := err.(database.SchemaError); ok {
+		for _, mismatch := range serr {
+			fmt.Fprintln(w, mismatch)
+		}
+		return fmt.Errorf("dbctl: %d schema mismatches found", len(serr))
+	}
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(w, "schema of %d models verified\n", len(models))
+	return nil
+}
+
+func schemaDump(ctx context.Context, w io.Writer, db *database.Database) error {
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	rows, err := conn.QueryContext(ctx, `SHOW FULL TABLES`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	var tables, views []string
+	for rows.Next() {
+		var table, tableType string
+		if err := rows.Scan(&table, &tableType); err != nil {
+			return err
+		}
+		if tableType == "VIEW" {
+			views = append(views, table)
+		} else {
+			tables = append(tables, table)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, table := range tables {
+		var name, statement string
+		if err := conn.QueryRowContext(ctx, fmt.Sprintf("SHOW CREATE TABLE `%s`", table)).Scan(&name, &statement); err != nil {
+			return err
+		}
+		fmt.Fprintf(w, "%s;\n\n", statement)
+	}
+
+	// Views are dumped after the tables they read from. SHOW CREATE VIEW returns
+	// the charset and collation of the client too.
+	for _, view := range views {
+		var name, statement, charset, collation string
+		if err := conn.QueryRowContext(ctx, fmt.Sprintf("SHOW CREATE VIEW `%s`", view)).Scan(&name, &statement, &charset, &collation); err != nil {
+			return err
+		}
+		fmt.Fprintf(w, "%s;\n\n", statement)
+	}
+
+	return nil
+}
@@ -0,0 +1,49 @@
+package dbctl
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMigrateCreate(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "migrations")
+
+	var buf bytes.Buffer
+	require.Nil(t, migrateCreate(&buf, dir, "create_hotels", time.Date(2018, 5, 3, 10, 20, 30, 0, time.UTC)))
+
+	_, err := os.Stat(filepath.Join(dir, "20180503102030_create_hotels.up.sql"))
+	require.Nil(t, err)
+	_, err = os.Stat(filepath.Join(dir, "20180503102030_create_hotels.down.sql"))
+	require.Nil(t, err)
+}
+
+func TestRunCreate(t *testing.T) {
+	dir := t.TempDir()
+
+	var buf bytes.Buffer
+	require.Nil(t, Run([]string{"-dir", dir, "migrate", "create", "add_name"}, &buf))
+
+	matches, err := filepath.Glob(filepath.Join(dir, "*_add_name.*.sql"))
+	require.Nil(t, err)
+	require.Len(t, matches, 2)
+}
+
+func TestRunInvalidArguments(t *testing.T) {
+	var buf bytes.Buffer
+	require.Equal(t, errUsage, Run([]string{"migrate"}, &buf))
+	require.Contains(t, buf.String(), "Usage: dbctl")
+
+	require.Equal(t, errUsage, Run([]string{"migrate", "create", "invalid name"}, &buf))
+	require.Equal(t, errUsage, Run([]string{"migrate", "down", "foo"}, &buf))
+	require.Equal(t, errUsage, Run([]string{"foo", "bar"}, &buf))
+}
+
+func TestSchemaVerifyWithoutModels(t *testing.T) {
+	var buf bytes.Buffer
+	require.EqualError(t, schemaVerify(&buf, nil, nil), "dbctl: no registered models, build your own binary importing the models package")
+}
@@ -0,0 +1,215 @@
+package database
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Dialect adapts the SQL generated by the library to a database engine. Queries
+// are built with "?" placeholders and identifiers escaped with backticks like in
+// MySQL, and the dialect rewrites them before sending them to the driver.
+//
+// The library registers the MySQL driver. To use other engines import their driver
+// and select the dialect when opening the connection:
+//
+//	import _ "github.com/lib/pq"
+//
+//	db, err := database.Open(credentials, database.WithDialect(database.PostgreSQL))
+//
+// SQLite only reads the path of the file from the credentials, and can open an
+// in-memory database for tests:
+//
+//	import _ "github.com/mattn/go-sqlite3"
+//
+//	db, err := database.Open(database.Credentials{Database: "test.db"}, database.WithDialect(database.SQLite))
+//
+// Some features are specific to MySQL and are not adapted: full-text search and
+// the FIELD and RAND sorters. Schema generation and verification, and migrations,
+// return an error with other dialects.
+type Dialect interface {
+	// DriverName returns the name of the database/sql driver to open connections.
+	DriverName() string
+
+	// DSN returns the data source name to open a connection with the credentials.
+	DSN(credentials Credentials) string
+
+	// Rebind adapts the placeholders and escaped identifiers of a query to the
+	// syntax of the database.
+	Rebind(query string) string
+
+	// Limit returns the clause to paginate the results of a query.
+	Limit(offset, limit int64) string
+
+	// TruncateSQL returns the statements to remove every row of a table and reset
+	// its auto increment counter.
+	TruncateSQL(table string) []string
+
+	// InsertReturning returns the clause to append to inserts to read the value
+	// generated for the auto increment column, or an empty string if the driver
+	// supports LastInsertId instead.
+	InsertReturning(column string) string
+}
+
+var (
+	// MySQL is the default dialect.
+	MySQL Dialect = new(mysqlDialect)
+
+	// PostgreSQL uses numbered placeholders ($1, $2, ...) and reads the generated
+	// IDs with RETURNING. It expects a driver registered with the name "postgres".
+	PostgreSQL Dialect = new(postgresDialect)
+
+	// SQLite accepts the same placeholders and escaped identifiers than MySQL. It
+	// expects a driver registered with the name "sqlite3".
+	SQLite Dialect = new(sqliteDialect)
+)
+
+// WithDialect is a database option that selects the SQL dialect of the database.
+// By default MySQL is used.
+func WithDialect(dialect Dialect) Option {
+	return func(db *Database) {
+		db.dialect = dialect
+	}
+}
+
+// dialectOf returns the dialect of the database, or MySQL if none was selected.
+func dialectOf(db *Database) Dialect {
+	if db.dialect == nil {
+		return MySQL
+	}
+
+	return db.dialect
+}
+
+// Dialect returns the SQL dialect of the database.
+func (db *Database) Dialect() Dialect {
+	return dialectOf(db)
+}
+
+// usesBackslashEscapes reports if the dialect escapes quotes with a backslash
+// inside strings. Only MySQL does it; the rest follow the SQL standard and only
+// double the quotes.
+func usesBackslashEscapes(dialect Dialect) bool {
+	_, ok := dialect.(*mysqlDialect)
+	return ok
+}
+
+type mysqlDialect struct{}
+
+func (d *mysqlDialect) DriverName() string {
+	return "mysql"
+}
+
+func (d *mysqlDialect) DSN(credentials Credentials) string {
+	return credentials.String()
+}
+
+func (d *mysqlDialect) Rebind(query string) string {
+	return query
+}
+
+func (d *mysqlDialect) Limit(offset, limit int64) string {
+	return fmt.Sprintf("LIMIT %d,%d", offset, limit)
+}
+
+func (d *mysqlDialect) TruncateSQL(table string) []string {
+	return []string{
+		fmt.Sprintf(`DELETE FROM %s`, table),
+		fmt.Sprintf(`ALTER TABLE %s AUTO_INCREMENT = 1`, table),
+	}
+}
+
+func (d *mysqlDialect) InsertReturning(column string) string {
+	return ""
+}
+
+type postgresDialect struct{}
+
+func (d *postgresDialect) DriverName() string {
+	return "postgres"
+}
+
+func (d *postgresDialect) DSN(credentials Credentials) string {
+	u := &url.URL{
+		Scheme: "postgres",
+		User:   url.UserPassword(credentials.User, credentials.Password),
+		Host:   credentials.Address,
+		Path:   "/" + credentials.Database,
+	}
+	query := url.Values{}
+	query.Set("sslmode", "disable")
+	u.RawQuery = query.Encode()
+
+	return u.String()
+}
+
+// Rebind replaces the placeholders with numbered ones and the identifiers escaped
+// with backticks with double quotes. String literals, identifiers in double quotes
+// and comments are not modified. Backslashes only escape quotes in E'...' strings.
+func (d *postgresDialect) Rebind(query string) string {
+	var sql strings.Builder
+	var n int
+	for i := 0; i < len(query); i++ {
+		if end := skipLiteral(query, i, false); end > i {
+			literal := query[i:end]
+			if query[i] == '`' {
+				literal = strings.TrimSuffix(strings.TrimPrefix(literal, "`"), "`")
+				literal = `"` + strings.Replace(literal, `"`, `""`, -1) + `"`
+			}
+			sql.WriteString(literal)
+			i = end - 1
+			continue
+		}
+
+		if query[i] == '?' {
+			n++
+			fmt.Fprintf(&sql, "$%d", n)
+			continue
+		}
+		sql.WriteByte(query[i])
+	}
+
+	return sql.String()
+}
+
+func (d *postgresDialect) Limit(offset, limit int64) string {
+	return fmt.Sprintf("LIMIT %d OFFSET %d", limit, offset)
+}
+
+func (d *postgresDialect) TruncateSQL(table string) []string {
+	return []string{fmt.Sprintf(`TRUNCATE TABLE %s RESTART IDENTITY`, table)}
+}
+
+func (d *postgresDialect) InsertReturning(column string) string {
+	return fmt.Sprintf(` RETURNING "%s"`, column)
+}
+
+type sqliteDialect struct{}
+
+func (d *sqliteDialect) DriverName() string {
+	return "sqlite3"
+}
+
+// DSN returns the path of the database file, that can be ":memory:" or include
+// options for the driver like "test.db?_journal_mode=WAL".
+func (d *sqliteDialect) DSN(credentials Credentials) string {
+	return credentials.Database
+}
+
+func (d *sqliteDialect) Rebind(query string) string {
+	return query
+}
+
+func (d *sqliteDialect) Limit(offset, limit int64) string {
+	return fmt.Sprintf("LIMIT %d OFFSET %d", limit, offset)
+}
+
+// TruncateSQL removes the rows of the table. Columns declared as INTEGER PRIMARY
+// KEY without AUTOINCREMENT start again from 1 when the table is empty.
+func (d *sqliteDialect) TruncateSQL(table string) []string {
+	return []string{fmt.Sprintf(`DELETE FROM %s`, table)}
+}
+
+func (d *sqliteDialect) InsertReturning(column string) string {
+	return ""
+}
@@ -2,6 +2,7 @@ package database
 
 import (
 	"errors"
+	"fmt"
 	"strings"
 )
 
@@ -50,3 +51,37 @@ func (merr MultiError) HasError() bool {
 
 	return false
 }
+
+// SchemaMismatch describes a single difference between a model and the table
+// that stores it in the database.
+type SchemaMismatch struct {
+	// Table name of the model.
+	Table string
+
+	// Column name, empty if the mismatch affects the whole table.
+	Column string
+
+	// Description of the problem.
+	Problem string
+}
+
+func (mismatch SchemaMismatch) String() string {
+	if mismatch.Column == "" {
+		return fmt.Sprintf("%s: %s", mismatch.Table, mismatch.Problem)
+	}
+
+	return fmt.Sprintf("%s.%s: %s", mismatch.Table, mismatch.Column, mismatch.Problem)
+}
+
+// SchemaError is returned from VerifySchema with the list of differences found
+// between the models and the tables of the database.
+type SchemaError []SchemaMismatch
+
+func (serr SchemaError) Error() string {
+	var msg []string
+	for _, mismatch := range serr {
+		msg = append(msg, mismatch.String())
+	}
+
+	return "database: schema mismatch: " + strings.Join(msg, "; ")
+}
@@ -0,0 +1,88 @@
+package database
+
+import (
+	"fmt"
+	"strings"
+)
+
+// MatchMode is the search modifier of a full-text search.
+type MatchMode int
+
+const (
+	// MatchNaturalLanguage interprets the query as a phrase in natural language.
+	MatchNaturalLanguage MatchMode = iota
+
+	// MatchBoolean interprets the query with the boolean operators of MySQL
+	// (+, -, *, quotes, ...). Clean user input with EscapeMatch before using it.
+	MatchBoolean
+
+	// MatchQueryExpansion runs a natural language search and a second one adding
+	// the most relevant words of the first results.
+	MatchQueryExpansion
+)
+
+func (mode MatchMode) modifier() string {
+	switch mode {
+	case MatchNaturalLanguage:
+		return "IN NATURAL LANGUAGE MODE"
+	case MatchBoolean:
+		return "IN BOOLEAN MODE"
+	case MatchQueryExpansion:
+		return "WITH QUERY EXPANSION"
+	}
+
+	panic(fmt.Sprintf("unknown match mode: %d", mode))
+}
+
+// MatchAgainst filters rows with a full-text search in the columns. The columns
+// should have a FULLTEXT index with exactly the same list of columns.
+func MatchAgainst(columns []string, query string, mode MatchMode) Condition {
+	return &sqlCondition{
+		sql:    fmt.Sprintf("%s > 0", matchSQL(columns, "?", mode)),
+		values: []interface{}{query},
+	}
+}
+
+// MatchRelevance sorts the rows by the relevance score of a full-text search in
+// the columns, from the most relevant to the least. Use it in combination with
+// a MatchAgainst condition with the same arguments.
+func MatchRelevance(columns []string, query string, mode MatchMode) Sorter {
+	return &sqlSorter{
+		sql:    fmt.Sprintf("%s DESC", matchSQL(columns, "?", mode)),
+		values: []interface{}{query},
+	}
+}
+
+func matchSQL(columns []string, query string, mode MatchMode) string {
+	if len(columns) == 0 {
+		panic("full-text search requires at least one column")
+	}
+	for _, column := range columns {
+		if !columnRe.MatchString(unquoteColumn(column)) {
+			panic(fmt.Sprintf("invalid column name in full-text search: %s", column))
+		}
+	}
+
+	return fmt.Sprintf("MATCH (%s) AGAINST (%s %s)", strings.Join(columns, ", "), query, mode.modifier())
+}
+
+// EscapeMatch cleans a value to insert it in a boolean full-text search without
+// unexpected operators. MySQL does not have a way to escape them, so they are replaced
+// with spaces. After using this function to clean the value you can add the operators
+// you need to the query.
+func EscapeMatch(str string) string {
+	return matchOperators.Replace(str)
+}
+
+var matchOperators = strings.NewReplacer(
+	"+", " ",
+	"-", " ",
+	"<", " ",
+	">", " ",
+	"(", " ",
+	")", " ",
+	"~", " ",
+	"*", " ",
+	`"`, " ",
+	"@", " ",
+)
@@ -0,0 +1,59 @@
+package database
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatchAgainstSQL(t *testing.T) {
+	cond := MatchAgainst([]string{"name", "description"}, "foo bar", MatchNaturalLanguage)
+	require.Equal(t, "MATCH (name, description) AGAINST (? IN NATURAL LANGUAGE MODE) > 0", cond.SQL())
+	require.Equal(t, []interface{}{"foo bar"}, cond.Values())
+
+	cond = MatchAgainst([]string{"name"}, "+foo -bar", MatchBoolean)
+	require.Equal(t, "MATCH (name) AGAINST (? IN BOOLEAN MODE) > 0", cond.SQL())
+
+	cond = MatchAgainst([]string{"name"}, "foo", MatchQueryExpansion)
+	require.Equal(t, "MATCH (name) AGAINST (? WITH QUERY EXPANSION) > 0", cond.SQL())
+}
+
+func TestMatchAgainstInvalidColumnPanics(t *testing.T) {
+	require.PanicsWithValue(t, "invalid column name in full-text search: name)", func() {
+		MatchAgainst([]string{"name)"}, "foo", MatchBoolean)
+	})
+	require.PanicsWithValue(t, "full-text search requires at least one column", func() {
+		MatchAgainst(nil, "foo", MatchBoolean)
+	})
+}
+
+func TestMatchRelevanceSQL(t *testing.T) {
+	sorter := MatchRelevance([]string{"name"}, "it's a test", MatchBoolean)
+	require.Equal(t, "MATCH (name) AGAINST (? IN BOOLEAN MODE) DESC", sorter.SQL())
+	require.Equal(t, []interface{}{"it's a test"}, sorter.(ValuesSorter).Values())
+}
+
+func TestEscapeMatch(t *testing.T) {
+	require.Equal(t, " foo  bar  baz   qux ", EscapeMatch(`+foo -bar "baz" (qux)`))
+	require.Equal(t, "foo  1 bar ", EscapeMatch(`foo @1 bar*`))
+}
+
+func TestMatchAgainst(t *testing.T) {
+	skipSQLite(t)
+	initDatabase(t)
+	defer closeDatabase()
+
+	require.Nil(t, testDB.Exec(`ALTER TABLE testing ADD FULLTEXT INDEX ft_name (name)`))
+
+	require.Nil(t, testings.Put(&testingModel{Code: "foo", Name: "beach hotel"}))
+	require.Nil(t, testings.Put(&testingModel{Code: "bar", Name: "mountain hotel"}))
+	require.Nil(t, testings.Put(&testingModel{Code: "baz", Name: "beach apartments beach"}))
+
+	query := "+" + EscapeMatch("beach")
+	var models []*testingModel
+	require.Nil(t, testings.FilterCond(MatchAgainst([]string{"name"}, query, MatchBoolean)).OrderSorter(MatchRelevance([]string{"name"}, query, MatchBoolean)).GetAll(&models))
+
+	require.Len(t, models, 2)
+	require.Equal(t, "baz", models[0].Code)
+	require.Equal(t, "foo", models[1].Code)
+}
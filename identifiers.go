@@ -0,0 +1,118 @@
+package database
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var (
+	columnRe = regexp.MustCompile(`^[a-zA-Z0-9_]+(\.[a-zA-Z0-9_]+)*$`)
+	aliasRe  = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+)
+
+// unquoteColumn removes the backticks of an escaped column name.
+func unquoteColumn(column string) string {
+	return strings.Replace(column, "`", "", -1)
+}
+
+// checkColumn panics if the column is not a plain identifier, optionally
+// qualified with the name of its table.
+func checkColumn(column string) {
+	if !columnRe.MatchString(unquoteColumn(column)) {
+		panic(fmt.Sprintf("invalid column name: %s", column))
+	}
+}
+
+// quoteColumn escapes a column name that can optionally be qualified with the
+// name or alias of its table.
+func quoteColumn(column string) string {
+	parts := strings.Split(unquoteColumn(column), ".")
+	for i, part := range parts {
+		parts[i] = fmt.Sprintf("`%s`", part)
+	}
+
+	return strings.Join(parts, ".")
+}
+
+// Column validates a column name against the model of the collection and returns
+// the name that should be used in queries. It accepts the name of the column or
+// the name of the Go field, and they can be qualified with the alias or table of
+// the collection or one of its joins:
+//
+//	c.Column("Name")    // "name"
+//	c.Column("h.Name")  // "h.name"
+//	c.Column("r.price") // "r.price"
+//
+// Unknown columns return an error. Filter, Order and the rest of the methods of
+// the collection that receive column names validate them the same way and return
+// the error when the query runs; see Collection.Err.
+func (c *Collection) Column(name string) (string, error) {
+	if !columnRe.MatchString(unquoteColumn(name)) {
+		return "", fmt.Errorf("database: invalid column name: %s", name)
+	}
+	name = unquoteColumn(name)
+
+	if column := c.meta.columnByName(name); column != "" {
+		return column, nil
+	}
+
+	if parts := strings.SplitN(name, ".", 2); len(parts) == 2 {
+		collections := []*Collection{c}
+		for _, join := range c.joins {
+			collections = append(collections, join.sub)
+		}
+		for _, coll := range collections {
+			if coll.qualifier() != parts[0] {
+				continue
+			}
+			if column := coll.meta.columnByName(parts[1]); column != "" {
+				return fmt.Sprintf("%s.%s", parts[0], column), nil
+			}
+		}
+	}
+
+	return "", fmt.Errorf("database: unknown column %s in model %T", name, c.model)
+}
+
+// mustColumn resolves a column with Column and panics if it is unknown.
+func (c *Collection) mustColumn(name string) string {
+	column, err := c.Column(name)
+	if err != nil {
+		panic(err)
+	}
+
+	return column
+}
+
+// resolveOperand resolves the left side of a filter with Column. Expressions with
+// parenthesis, like "LOWER(name)", are returned unchanged.
+func (c *Collection) resolveOperand(operand string) (string, error) {
+	if strings.Contains(operand, "(") {
+		return operand, nil
+	}
+
+	return c.Column(operand)
+}
+
+// columnByName returns the unescaped column name of a struct field or column
+// of the model, or an empty string if there is no such column.
+func (meta *modelMetadata) columnByName(name string) string {
+	if i := meta.fieldPosition(name); i >= 0 {
+		return unquoteColumn(meta.fields[i].name)
+	}
+
+	return ""
+}
+
+// fieldPosition returns the position of the struct field or column of the model
+// with that name, or -1 if there is no such column.
+func (meta *modelMetadata) fieldPosition(name string) int {
+	for i, field := range meta.fields {
+		if unquoteColumn(field.name) == name || field.field == name {
+			return i
+		}
+	}
+
+	return -1
+}
@@ -0,0 +1,159 @@
+package database
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestColumn(t *testing.T) {
+	children := newCollection(new(Database), new(testingRelChild)).Alias("c")
+	c := newCollection(new(Database), new(testingRelParent)).Alias("p").Join(children, "c.parent = p.id")
+
+	tests := []struct {
+		name, column string
+	}{
+		{"id", "id"},
+		{"ID", "id"},
+		{"`id`", "id"},
+		{"p.ID", "p.id"},
+		{"c.Foo", "c.foo"},
+		{"c.parent", "c.parent"},
+	}
+	for _, test := range tests {
+		column, err := c.Column(test.name)
+		require.Nil(t, err, test.name)
+		require.Equal(t, test.column, column, test.name)
+	}
+}
+
+func TestColumnEmbedded(t *testing.T) {
+	c := newCollection(new(Database), new(testingEmbeddedModel))
+
+	column, err := c.Column("Billing.City")
+	require.Nil(t, err)
+	require.Equal(t, "billing_city", column)
+}
+
+func TestColumnUnknown(t *testing.T) {
+	c := newCollection(new(Database), new(testingRelParent)).Alias("p")
+
+	_, err := c.Column("foo")
+	require.EqualError(t, err, "database: unknown column foo in model *database.testingRelParent")
+
+	_, err = c.Column("c.id")
+	require.EqualError(t, err, "database: unknown column c.id in model *database.testingRelParent")
+
+	_, err = c.Column("id; DROP TABLE foo")
+	require.EqualError(t, err, "database: invalid column name: id; DROP TABLE foo")
+}
+
+func TestOrderFieldName(t *testing.T) {
+	c := newCollection(new(Database), new(testingModel)).Alias("x").Order("-Name").Order("x.Code")
+	require.Nil(t, c.Err())
+	require.Equal(t, "`name` DESC", c.orders[0].SQL())
+	require.Equal(t, "`x`.`code` ASC", c.orders[1].SQL())
+}
+
+func TestOrderUnknownColumn(t *testing.T) {
+	tests := []struct {
+		column, err string
+	}{
+		{"foo", "database: unknown column foo in model *database.testingModel"},
+		{"-y.Name", "database: unknown column y.Name in model *database.testingModel"},
+		{"name`; DROP TABLE testing", "database: invalid column name: name`; DROP TABLE testing"},
+		{"LOWER(name)", "database: invalid column name: LOWER(name)"},
+	}
+	for _, test := range tests {
+		c := newCollection(new(Database), new(testingModel)).Order(test.column).Order("code")
+		require.EqualError(t, c.Err(), test.err, test.column)
+		require.Len(t, c.orders, 1, test.column)
+
+		require.EqualError(t, c.GetAll(new([]*testingModel)), test.err, test.column)
+		_, err := c.Count()
+		require.EqualError(t, err, test.err, test.column)
+	}
+}
+
+func TestFilterFieldName(t *testing.T) {
+	c := newCollection(new(Database), new(testingModel)).Filter("Name >", "foo").FilterIsNil("Code")
+	require.Nil(t, c.Err())
+	require.Equal(t, "name > ?", c.conditions[0].SQL())
+	require.Equal(t, "code IS NULL", c.conditions[1].SQL())
+}
+
+func TestFilterQualifiedColumns(t *testing.T) {
+	children := newCollection(new(Database), new(testingRelChild)).Alias("c")
+	c := newCollection(new(Database), new(testingRelParent)).Alias("p").
+		Join(children, "c.parent = p.id").
+		Filter("p.ID", 3).
+		Filter("LOWER(c.foo) =", "baz").
+		FilterIsNil("c.Foo")
+	require.Nil(t, c.Err())
+	require.Equal(t, "p.id = ?", c.conditions[0].SQL())
+	require.Equal(t, "LOWER(c.foo) = ?", c.conditions[1].SQL())
+	require.Equal(t, "c.foo IS NULL", c.conditions[2].SQL())
+}
+
+func TestFilterUnknownColumns(t *testing.T) {
+	tests := []struct {
+		name string
+		c    *Collection
+		err  string
+	}{
+		{
+			name: "plain",
+			c:    newCollection(new(Database), new(testingModel)).Filter("foo", 3),
+			err:  "database: unknown column foo in model *database.testingModel",
+		},
+		{
+			name: "unknown alias",
+			c:    newCollection(new(Database), new(testingModel)).Filter("p.Name >", "bar"),
+			err:  "database: unknown column p.Name in model *database.testingModel",
+		},
+		{
+			name: "is nil",
+			c:    newCollection(new(Database), new(testingModel)).FilterIsNil("deleted"),
+			err:  "database: unknown column deleted in model *database.testingModel",
+		},
+		{
+			name: "in",
+			c:    newCollection(new(Database), new(testingRelParent)).FilterIn("id", newCollection(new(Database), new(testingRelChild)), "foo_id"),
+			err:  "database: unknown column foo_id in model *database.testingRelChild",
+		},
+		{
+			name: "subquery",
+			c:    newCollection(new(Database), new(testingRelParent)).FilterExists(newCollection(new(Database), new(testingRelChild)).Filter("bar", 1), "parent = id"),
+			err:  "database: unknown column bar in model *database.testingRelChild",
+		},
+		{
+			name: "first error",
+			c:    newCollection(new(Database), new(testingModel)).Filter("foo", 3).Order("bar"),
+			err:  "database: unknown column foo in model *database.testingModel",
+		},
+	}
+	for _, test := range tests {
+		require.EqualError(t, test.c.Err(), test.err, test.name)
+		require.Empty(t, test.c.conditions, test.name)
+		require.EqualError(t, test.c.First(new(testingModel)), test.err, test.name)
+	}
+}
+
+func TestAliasInvalid(t *testing.T) {
+	require.PanicsWithValue(t, "invalid alias: p; DROP", func() {
+		newCollection(new(Database), new(testingModel)).Alias("p; DROP")
+	})
+}
+
+func TestConditionColumnsValidated(t *testing.T) {
+	require.PanicsWithValue(t, "invalid column name: foo)", func() {
+		FilterIsNil("foo)")
+	})
+	require.PanicsWithValue(t, "invalid column name in Filter: foo)", func() {
+		Filter("foo) >", 3)
+	})
+
+	cond := CompareJSON("data", "$.name'", "foo")
+	require.Equal(t, "JSON_EXTRACT(data, ?) = ?", cond.SQL())
+	require.Equal(t, []interface{}{"$.name'", "foo"}, cond.Values())
+}
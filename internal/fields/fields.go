@@ -0,0 +1,8 @@
+// Package fields gives the other packages of the module access to the fields of
+// the models without adding it to the public API of package database.
+package fields
+
+// Pointer returns a pointer to the field of the instance that stores a column of
+// the collection. The name can also be the name of the Go field. Package database
+// sets it when it is initialized.
+var Pointer func(collection, instance interface{}, name string) (interface{}, error)
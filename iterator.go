@@ -1,13 +1,16 @@
 package database
 
 import (
+	"context"
 	"database/sql"
+	"iter"
+	"reflect"
 )
 
 // Iterator helps to loop through rows of a collection retrieving a single model each time.
 type Iterator struct {
-	rows  *sql.Rows
-	props []*Property
+	rows *sql.Rows
+	meta *modelMetadata
 }
 
 // Close finishes the iteration. Do not use the iterator after closing it.
@@ -20,8 +23,6 @@ func (it *Iterator) Close() {
 //
 // When the iterator reaches the end of the collection it returns ErrDone.
 func (it *Iterator) Next(model Model) error {
-	modelProps := updatedProps(it.props, model)
-
 	if err := it.rows.Err(); err != nil {
 		return err
 	}
@@ -36,15 +37,105 @@ func (it *Iterator) Next(model Model) error {
 		return ErrDone
 	}
 
-	ptrs := make([]interface{}, len(modelProps))
-	for i, prop := range modelProps {
-		ptrs[i] = prop.Pointer
-	}
-	if err := it.rows.Scan(ptrs...); err != nil {
+	if err := it.rows.Scan(it.meta.scanPointers(model)...); err != nil {
 		return err
 	}
 
-	modelProps = updatedProps(it.props, model)
+	return model.Tracking().AfterGet(it.meta.modelProps(model))
+}
+
+// All returns an iterator over the models of the collection to use in a for range
+// loop. The rows are closed automatically when the loop finishes, even if it
+// breaks early. If the query fails the error is yielded once and the loop ends:
+//
+//	for model, err := range c.All() {
+//	  if err != nil {
+//	    return err
+//	  }
+//	  hotel := model.(*Hotel)
+//	}
+func (c *Collection) All() iter.Seq2[Model, error] {
+	return allModels[Model](c)
+}
+
+// Stream reads the models of the collection in the background and sends them
+// through a channel with the buffer size. The channel of models is closed when
+// all of them have been sent. If the query fails or the context is cancelled the
+// error will be sent through the error channel, that is closed after the models one.
+//
+//	models, errs := c.Stream(ctx, 100)
+//	for model := range models {
+//	  ...
+//	}
+//	if err := <-errs; err != nil {
+//	  return err
+//	}
+//
+// Cancel the context if you stop reading the models before the end to release
+// the connection.
+func (c *Collection) Stream(ctx context.Context, buffer int) (<-chan Model, <-chan error) {
+	return streamModels[Model](ctx, c, buffer)
+}
+
+func allModels[T Model](c *Collection) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		var zero T
+
+		it, err := c.Iterator()
+		if err != nil {
+			yield(zero, err)
+			return
+		}
+		defer it.Close()
+
+		for {
+			model := reflect.New(reflect.TypeOf(c.model).Elem()).Interface().(T)
+			if err := it.Next(model); err != nil {
+				if err != ErrDone {
+					yield(zero, err)
+				}
+				return
+			}
+
+			if !yield(model, nil) {
+				return
+			}
+		}
+	}
+}
+
+func streamModels[T Model](ctx context.Context, c *Collection, buffer int) (<-chan T, <-chan error) {
+	models := make(chan T, buffer)
+	errs := make(chan error, 1)
+
+	go func() {
+		defer close(errs)
+		defer close(models)
+
+		it, err := c.iterator(ctx)
+		if err != nil {
+			errs <- err
+			return
+		}
+		defer it.Close()
+
+		for {
+			model := reflect.New(reflect.TypeOf(c.model).Elem()).Interface().(T)
+			if err := it.Next(model); err != nil {
+				if err != ErrDone {
+					errs <- err
+				}
+				return
+			}
+
+			select {
+			case models <- model:
+			case <-ctx.Done():
+				errs <- ctx.Err()
+				return
+			}
+		}
+	}()
 
-	return model.Tracking().AfterGet(modelProps)
+	return models, errs
 }
@@ -1,6 +1,7 @@
 package database
 
 import (
+	"context"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -27,3 +28,61 @@ func TestIteratorNextCallHooks(t *testing.T) {
 	require.True(t, models[1].IsInserted())
 	require.EqualValues(t, 0, models[1].Tracking().StoredRevision())
 }
+
+func TestAll(t *testing.T) {
+	initDatabase(t)
+	defer closeDatabase()
+
+	require.Nil(t, testingsAuto.Put(new(testingAutoModel)))
+	require.Nil(t, testingsAuto.Put(new(testingAutoModel)))
+	require.Nil(t, testingsAuto.Put(new(testingAutoModel)))
+
+	var ids []int64
+	for model, err := range testingsAuto.Clone().Order("id").All() {
+		require.Nil(t, err)
+		ids = append(ids, model.(*testingAutoModel).ID)
+		if len(ids) == 2 {
+			break
+		}
+	}
+	require.Equal(t, []int64{1, 2}, ids)
+
+	ids = nil
+	for model, err := range NewTypedCollection[*testingAutoModel](testDB).Order("-id").All() {
+		require.Nil(t, err)
+		ids = append(ids, model.ID)
+	}
+	require.Equal(t, []int64{3, 2, 1}, ids)
+}
+
+func TestStream(t *testing.T) {
+	initDatabase(t)
+	defer closeDatabase()
+
+	require.Nil(t, testingsAuto.Put(new(testingAutoModel)))
+	require.Nil(t, testingsAuto.Put(new(testingAutoModel)))
+
+	models, errs := NewTypedCollection[*testingAutoModel](testDB).Order("id").Stream(context.Background(), 1)
+
+	var ids []int64
+	for model := range models {
+		ids = append(ids, model.ID)
+	}
+	require.Nil(t, <-errs)
+	require.Equal(t, []int64{1, 2}, ids)
+}
+
+func TestStreamCancel(t *testing.T) {
+	initDatabase(t)
+	defer closeDatabase()
+
+	require.Nil(t, testingsAuto.Put(new(testingAutoModel)))
+	require.Nil(t, testingsAuto.Put(new(testingAutoModel)))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	models, errs := testingsAuto.Stream(ctx, 0)
+	<-models
+	cancel()
+
+	require.Equal(t, context.Canceled, <-errs)
+}
@@ -0,0 +1,349 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+type collectionJoin struct {
+	kind string
+	sub  *Collection
+	on   string
+}
+
+// Join adds another collection to the query with an INNER JOIN. The on statement
+// relates both tables and the filters of the other collection are added to it.
+// Use aliases in both collections to refer to the columns in the statement, in
+// the filters and in the orders:
+//
+//	hotels := db.Collection(new(Hotel)).Alias("h")
+//	rooms := db.Collection(new(Room)).Alias("r").Filter("r.available", true)
+//	hotels.Join(rooms, "r.hotel_id = h.id").Order("-r.price")
+//
+// Only the columns of the root model are read by Get, GetAll, First and the
+// iterators. To read the columns of the joined models too use GetAllComposite.
+// Put, Delete and DeleteMulti return an error in collections with joins.
+func (c *Collection) Join(other *Collection, on string) *Collection {
+	return c.join("JOIN", other, on)
+}
+
+// LeftJoin adds another collection to the query with a LEFT JOIN. See Join for
+// documentation.
+func (c *Collection) LeftJoin(other *Collection, on string) *Collection {
+	return c.join("LEFT JOIN", other, on)
+}
+
+func (c *Collection) join(kind string, other *Collection, on string) *Collection {
+	if on == "" {
+		panic("on SQL statement is required to join collections")
+	}
+	if other.err != nil {
+		return c.fail(other.err)
+	}
+
+	c.joins = append(c.joins, &collectionJoin{
+		kind: kind,
+		sub:  other.Clone(),
+		on:   on,
+	})
+	return c
+}
+
+func (c *Collection) sqlJoins() []*sqlJoin {
+	var joins []*sqlJoin
+	for _, join := range c.joins {
+		joins = append(joins, &sqlJoin{
+			kind:       join.kind,
+			table:      join.sub.model.TableName(),
+			alias:      join.sub.alias,
+			on:         join.on,
+			conditions: join.sub.conditions,
+		})
+	}
+
+	return joins
+}
+
+// qualifier returns the name that refers to the table of the collection in queries.
+func (c *Collection) qualifier() string {
+	if c.alias != "" {
+		return c.alias
+	}
+
+	return c.model.TableName()
+}
+
+// column qualifies an escaped column name with the table of the collection when
+// there are joins that could make it ambiguous.
+func (c *Collection) column(name string) string {
+	if len(c.joins) == 0 {
+		return name
+	}
+
+	return fmt.Sprintf("%s.%s", c.qualifier(), name)
+}
+
+type compositeTarget struct {
+	field    int
+	coll     *Collection
+	nullable bool
+}
+
+// GetAllComposite reads the columns of the root model and every joined model at
+// the same time. It receives a pointer to an empty slice of pointers to a struct
+// with a field for each model:
+//
+//	type HotelRoom struct {
+//	  Hotel *Hotel
+//	  Room  *Room
+//	}
+//
+// Fields are matched with the collections by the type of the model. If the same
+// model is joined multiple times, tag the fields with the alias of each collection:
+//
+//	type RoomPair struct {
+//	  Room  *Room `db:"r1"`
+//	  Other *Room `db:"r2"`
+//	}
+//
+// Models of a LEFT JOIN will be nil when there is no row to join.
+func (c *Collection) GetAllComposite(results interface{}) error {
+	if c.err != nil {
+		return c.err
+	}
+
+	v := reflect.ValueOf(results)
+	t := reflect.TypeOf(results)
+	if v.Kind() != reflect.Ptr || t.Elem().Kind() != reflect.Slice {
+		return fmt.Errorf("database: pass a pointer to a slice to GetAllComposite")
+	}
+	v = v.Elem()
+	t = t.Elem()
+	if t.Elem().Kind() != reflect.Ptr || t.Elem().Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("database: pass a slice of pointers to structs to GetAllComposite")
+	}
+	if len(c.preloads) > 0 {
+		return fmt.Errorf("database: GetAllComposite cannot preload relations")
+	}
+	composite := t.Elem().Elem()
+
+	targets := []*compositeTarget{{coll: c}}
+	for _, join := range c.joins {
+		targets = append(targets, &compositeTarget{
+			coll:     join.sub,
+			nullable: join.kind == "LEFT JOIN",
+		})
+	}
+
+	var cols []string
+	used := map[int]bool{}
+	for _, target := range targets {
+		target.field = -1
+		for i := 0; i < composite.NumField(); i++ {
+			field := composite.Field(i)
+			if used[i] || field.Type != reflect.TypeOf(target.coll.model) {
+				continue
+			}
+			if tag := field.Tag.Get("db"); tag != "" && tag != target.coll.alias {
+				continue
+			}
+			target.field = i
+			used[i] = true
+			break
+		}
+		if target.field == -1 {
+			return fmt.Errorf("database: no field in %s for the model %T", composite, target.coll.model)
+		}
+
+		for _, prop := range target.coll.props {
+			cols = append(cols, fmt.Sprintf("%s.%s", target.coll.qualifier(), prop.Name))
+		}
+	}
+
+	b := &sqlBuilder{
+		dialect:    c.dialect,
+		table:      c.model.TableName(),
+		conditions: c.conditions,
+		limit:      c.limit,
+		offset:     c.offset,
+		orders:     c.orders,
+		alias:      c.alias,
+		joins:      c.sqlJoins(),
+	}
+	statement, values := b.SelectSQLCols(cols...)
+	if c.debug {
+		log.Println("database [GetAllComposite]:", statement)
+	}
+
+	rows, err := c.query(statement, values...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	dest := reflect.MakeSlice(t, 0, 0)
+	for rows.Next() {
+		result := reflect.New(composite)
+
+		var pointers []interface{}
+		models := make([]Model, len(targets))
+		nulls := make([][]*nullableDest, len(targets))
+		for i, target := range targets {
+			models[i] = reflect.New(reflect.TypeOf(target.coll.model).Elem()).Interface().(Model)
+			for _, ptr := range target.coll.meta.scanPointers(models[i]) {
+				if target.nullable {
+					null := &nullableDest{dest: ptr}
+					nulls[i] = append(nulls[i], null)
+					ptr = null
+				}
+				pointers = append(pointers, ptr)
+			}
+		}
+		if err := rows.Scan(pointers...); err != nil {
+			return err
+		}
+
+		for i, target := range targets {
+			if target.nullable && allNull(nulls[i]) {
+				continue
+			}
+
+			if err := models[i].Tracking().AfterGet(target.coll.meta.modelProps(models[i])); err != nil {
+				return err
+			}
+			result.Elem().Field(target.field).Set(reflect.ValueOf(models[i]))
+		}
+
+		dest = reflect.Append(dest, result)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	v.Set(dest)
+
+	return nil
+}
+
+func allNull(nulls []*nullableDest) bool {
+	for _, null := range nulls {
+		if null.valid {
+			return false
+		}
+	}
+
+	return true
+}
+
+// nullableDest scans a column that may be NULL when the joined row does not
+// exist, even if the field of the model does not accept NULL values.
+type nullableDest struct {
+	dest  interface{}
+	valid bool
+}
+
+func (null *nullableDest) Scan(src interface{}) error {
+	if src == nil {
+		return nil
+	}
+	null.valid = true
+
+	if scanner, ok := null.dest.(sql.Scanner); ok {
+		return scanner.Scan(src)
+	}
+
+	return assignValue(reflect.ValueOf(null.dest).Elem(), src)
+}
+
+// assignValue stores a value returned by the driver in a field of a model.
+func assignValue(dest reflect.Value, src interface{}) error {
+	if dest.Kind() == reflect.Ptr {
+		dest.Set(reflect.New(dest.Type().Elem()))
+		return assignValue(dest.Elem(), src)
+	}
+
+	switch src := src.(type) {
+	case []byte:
+		if dest.Type() == bytesType {
+			dest.SetBytes(append([]byte(nil), src...))
+			return nil
+		}
+		return assignString(dest, string(src))
+
+	case string:
+		return assignString(dest, src)
+
+	case time.Time:
+		if dest.Type() == timeType {
+			dest.Set(reflect.ValueOf(src))
+			return nil
+		}
+	}
+
+	sv := reflect.ValueOf(src)
+	if dest.Kind() == reflect.Bool && sv.Kind() == reflect.Int64 {
+		dest.SetBool(sv.Int() != 0)
+		return nil
+	}
+	if sv.Type().ConvertibleTo(dest.Type()) {
+		dest.Set(sv.Convert(dest.Type()))
+		return nil
+	}
+
+	return fmt.Errorf("database: cannot assign %T to a field of type %s", src, dest.Type())
+}
+
+func assignString(dest reflect.Value, src string) error {
+	switch dest.Kind() {
+	case reflect.String:
+		dest.SetString(src)
+		return nil
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(src, 10, dest.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("database: cannot assign %q to a field of type %s: %s", src, dest.Type(), err)
+		}
+		dest.SetInt(n)
+		return nil
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(src, 10, dest.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("database: cannot assign %q to a field of type %s: %s", src, dest.Type(), err)
+		}
+		dest.SetUint(n)
+		return nil
+
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(src, dest.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("database: cannot assign %q to a field of type %s: %s", src, dest.Type(), err)
+		}
+		dest.SetFloat(n)
+		return nil
+
+	case reflect.Bool:
+		b, err := strconv.ParseBool(src)
+		if err != nil {
+			return fmt.Errorf("database: cannot assign %q to a field of type %s: %s", src, dest.Type(), err)
+		}
+		dest.SetBool(b)
+		return nil
+	}
+
+	if dest.Type() == timeType {
+		parsed, err := time.Parse("2006-01-02 15:04:05", src)
+		if err != nil {
+			return fmt.Errorf("database: cannot assign %q to a field of type %s: %s", src, dest.Type(), err)
+		}
+		dest.Set(reflect.ValueOf(parsed))
+		return nil
+	}
+
+	return fmt.Errorf("database: cannot assign %q to a field of type %s", src, dest.Type())
+}
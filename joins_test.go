@@ -0,0 +1,121 @@
+package database
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestOrderQualifiedColumn(t *testing.T) {
+	children := newCollection(new(Database), new(testingRelChild)).Alias("c")
+	c := newCollection(new(Database), new(testingRelParent)).Join(children, "c.parent = testing_relparent.id").Order("-c.foo").Order("id")
+	require.Nil(t, c.Err())
+	require.Len(t, c.orders, 2)
+	require.Equal(t, "`c`.`foo` DESC", c.orders[0].SQL())
+	require.Equal(t, "`id` ASC", c.orders[1].SQL())
+}
+
+func TestJoinWrites(t *testing.T) {
+	children := newCollection(new(Database), new(testingRelChild)).Alias("r").Filter("r.foo", "bar")
+	c := newCollection(new(Database), new(testingRelParent)).Alias("p").Join(children, "r.parent = p.id")
+
+	require.EqualError(t, c.Put(new(testingRelParent)), "database: cannot put models in a collection with joins")
+	require.EqualError(t, c.Delete(&testingRelParent{ID: 1}), "database: cannot delete models in a collection with joins")
+	require.EqualError(t, c.DeleteMulti([]int64{1}), "database: cannot delete models in a collection with joins")
+}
+
+func insertJoinFixtures(t *testing.T) {
+	require.Nil(t, testingsRelParent.Put(new(testingRelParent)))
+	require.Nil(t, testingsRelParent.Put(new(testingRelParent)))
+	require.Nil(t, testingsRelParent.Put(new(testingRelParent)))
+
+	require.Nil(t, testingsRelChild.Put(&testingRelChild{Parent: 1, Foo: "b"}))
+	require.Nil(t, testingsRelChild.Put(&testingRelChild{Parent: 2, Foo: "a"}))
+	require.Nil(t, testingsRelChild.Put(&testingRelChild{Parent: 2, Foo: "c"}))
+}
+
+func TestJoin(t *testing.T) {
+	initDatabase(t)
+	defer closeDatabase()
+	insertJoinFixtures(t)
+
+	children := testingsRelChild.Clone().Alias("c").Filter("c.foo !=", "c")
+	parents := testingsRelParent.Clone().Alias("p").Join(children, "c.parent = p.id").Order("c.foo")
+
+	var models []*testingRelParent
+	require.Nil(t, parents.GetAll(&models))
+
+	require.Len(t, models, 2)
+	require.EqualValues(t, 2, models[0].ID)
+	require.EqualValues(t, 1, models[1].ID)
+}
+
+func TestJoinGet(t *testing.T) {
+	initDatabase(t)
+	defer closeDatabase()
+	insertJoinFixtures(t)
+
+	children := testingsRelChild.Clone().Alias("c").Filter("c.foo", "a")
+	parents := testingsRelParent.Clone().Alias("p").Join(children, "c.parent = p.id")
+
+	m := &testingRelParent{ID: 2}
+	require.Nil(t, parents.Get(m))
+
+	m = &testingRelParent{ID: 1}
+	require.EqualError(t, parents.Get(m), ErrNoSuchEntity.Error())
+}
+
+type testingParentChild struct {
+	Parent *testingRelParent
+	Child  *testingRelChild
+}
+
+func TestGetAllComposite(t *testing.T) {
+	initDatabase(t)
+	defer closeDatabase()
+	insertJoinFixtures(t)
+
+	children := testingsRelChild.Clone().Alias("c")
+	parents := testingsRelParent.Clone().Alias("p").LeftJoin(children, "c.parent = p.id").Order("p.id").Order("c.foo")
+
+	var results []*testingParentChild
+	require.Nil(t, parents.GetAllComposite(&results))
+
+	require.Len(t, results, 4)
+
+	require.EqualValues(t, 1, results[0].Parent.ID)
+	require.Equal(t, "b", results[0].Child.Foo)
+
+	require.EqualValues(t, 2, results[1].Parent.ID)
+	require.Equal(t, "a", results[1].Child.Foo)
+	require.True(t, results[1].Child.IsInserted())
+
+	require.EqualValues(t, 2, results[2].Parent.ID)
+	require.Equal(t, "c", results[2].Child.Foo)
+
+	require.EqualValues(t, 3, results[3].Parent.ID)
+	require.Nil(t, results[3].Child)
+}
+
+func TestNullableDest(t *testing.T) {
+	var name string
+	null := &nullableDest{dest: &name}
+	require.Nil(t, null.Scan(nil))
+	require.False(t, null.valid)
+
+	require.Nil(t, null.Scan([]byte("foo")))
+	require.True(t, null.valid)
+	require.Equal(t, "foo", name)
+
+	var id int64
+	require.Nil(t, (&nullableDest{dest: &id}).Scan([]byte("42")))
+	require.EqualValues(t, 42, id)
+
+	var n int32
+	require.Nil(t, (&nullableDest{dest: &n}).Scan(int64(7)))
+	require.EqualValues(t, 7, n)
+
+	var b bool
+	require.Nil(t, (&nullableDest{dest: &b}).Scan(int64(1)))
+	require.True(t, b)
+}
@@ -0,0 +1,268 @@
+package database
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"reflect"
+	"strings"
+)
+
+// Key is an ordered tuple with the values of the primary keys of a model, in the
+// same order the primary keys are declared in the struct. It identifies rows of
+// models with composite primary keys in GetMulti and DeleteMulti:
+//
+//	keys := []database.Key{
+//	  database.NewKey(hotelID, roomID),
+//	  database.NewKey(hotelID, otherRoomID),
+//	}
+//	err := db.Collection(new(HotelRoom)).GetMulti(keys, &models)
+type Key []interface{}
+
+// NewKey builds a new key with the values of the primary keys.
+func NewKey(values ...interface{}) Key {
+	return Key(values)
+}
+
+// KeyOf returns the key of a model with the current values of its primary keys.
+func KeyOf(instance Model) Key {
+	meta, err := modelMetadataOf(reflect.TypeOf(instance))
+	if err != nil {
+		panic(err)
+	}
+
+	var key Key
+	v := reflect.ValueOf(instance).Elem()
+	for _, field := range meta.primaryKeys() {
+		key = append(key, v.FieldByIndex(field.index).Interface())
+	}
+
+	return key
+}
+
+// Encode returns an opaque representation of the key that can be sent to clients
+// as a pagination token. Use DecodeKey of the collection to read it again.
+func (key Key) Encode() string {
+	encoded, err := json.Marshal([]interface{}(key))
+	if err != nil {
+		panic(fmt.Sprintf("cannot encode key %v: %s", key, err))
+	}
+
+	return base64.RawURLEncoding.EncodeToString(encoded)
+}
+
+// String returns a readable representation of the key.
+func (key Key) String() string {
+	parts := make([]string, len(key))
+	for i, value := range key {
+		parts[i] = fmt.Sprintf("%v", value)
+	}
+
+	return "(" + strings.Join(parts, ", ") + ")"
+}
+
+// DecodeKey reads a key encoded with Key.Encode and converts its values to the
+// types of the primary keys of the collection model.
+func (c *Collection) DecodeKey(token string) (Key, error) {
+	decoded, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, fmt.Errorf("database: invalid key token: %s", err)
+	}
+
+	var values []interface{}
+	decoder := json.NewDecoder(bytes.NewReader(decoded))
+	decoder.UseNumber()
+	if err := decoder.Decode(&values); err != nil {
+		return nil, fmt.Errorf("database: invalid key token: %s", err)
+	}
+	for i, value := range values {
+		if n, ok := value.(json.Number); ok {
+			if values[i], err = n.Int64(); err != nil {
+				return nil, fmt.Errorf("database: invalid key token: %s", err)
+			}
+		}
+	}
+
+	return c.meta.normalizeKey(Key(values))
+}
+
+// primaryKeys returns the metadata of the primary keys in declaration order.
+func (meta *modelMetadata) primaryKeys() []*fieldMetadata {
+	var pks []*fieldMetadata
+	for _, field := range meta.fields {
+		if field.pk {
+			pks = append(pks, field)
+		}
+	}
+
+	return pks
+}
+
+// normalizeKey checks the length of the key and converts its values to the types
+// of the primary keys, so keys built with untyped constants match the models.
+// Floats are only accepted in integer keys if they have no decimals.
+func (meta *modelMetadata) normalizeKey(key Key) (Key, error) {
+	pks := meta.primaryKeys()
+	if len(key) != len(pks) {
+		return nil, fmt.Errorf("database: key %s has %d values and the model has %d primary keys", key, len(key), len(pks))
+	}
+
+	normalized := make(Key, len(key))
+	for i, value := range key {
+		v := reflect.ValueOf(value)
+		if !v.IsValid() || !v.Type().ConvertibleTo(pks[i].typ) || (v.Kind() == reflect.String) != (pks[i].typ.Kind() == reflect.String) {
+			return nil, fmt.Errorf("database: cannot use %v as the value of the primary key %s", value, pks[i].field)
+		}
+		if isFloat(v.Kind()) && !isFloat(pks[i].typ.Kind()) {
+			if f := v.Float(); f != math.Trunc(f) || math.IsInf(f, 0) {
+				return nil, fmt.Errorf("database: cannot use %v as the value of the primary key %s", value, pks[i].field)
+			}
+		}
+		normalized[i] = v.Convert(pks[i].typ).Interface()
+	}
+
+	return normalized, nil
+}
+
+func isFloat(kind reflect.Kind) bool {
+	return kind == reflect.Float32 || kind == reflect.Float64
+}
+
+// keysOf converts a slice of keys passed to GetMulti or DeleteMulti to a list of
+// normalized keys. Models with a single primary key accept a slice of plain values.
+func (meta *modelMetadata) keysOf(keys interface{}) ([]Key, error) {
+	v := reflect.ValueOf(keys)
+	if v.Kind() != reflect.Slice {
+		return nil, fmt.Errorf("database: pass a slice of keys")
+	}
+
+	pks := meta.primaryKeys()
+	if len(pks) == 0 {
+		return nil, fmt.Errorf("database: model has no primary keys")
+	}
+
+	result := make([]Key, v.Len())
+	for i := range result {
+		key, ok := v.Index(i).Interface().(Key)
+		if !ok {
+			if len(pks) > 1 {
+				return nil, fmt.Errorf("database: pass a slice of Key with multiple primary keys")
+			}
+			key = Key{v.Index(i).Interface()}
+		}
+
+		normalized, err := meta.normalizeKey(key)
+		if err != nil {
+			return nil, err
+		}
+		result[i] = normalized
+	}
+
+	return result, nil
+}
+
+// autoIncrementProp returns the primary key that receives the auto increment ID
+// of new rows. Models with a single primary key use it if it is an int64. Models
+// with a composite primary key use the only int64 one that is empty, if any.
+func autoIncrementProp(props []*Property) *Property {
+	var pks []*Property
+	for _, prop := range props {
+		if prop.PrimaryKey {
+			pks = append(pks, prop)
+		}
+	}
+	if len(pks) == 1 {
+		if _, ok := pks[0].Value.(int64); ok {
+			return pks[0]
+		}
+		return nil
+	}
+
+	var auto *Property
+	for _, prop := range pks {
+		if _, ok := prop.Value.(int64); ok && isZero(prop.Value) {
+			if auto != nil {
+				return nil
+			}
+			auto = prop
+		}
+	}
+
+	return auto
+}
+
+// keyString returns a comparable representation of a normalized key to index
+// the models by their primary keys.
+func keyString(key Key) string {
+	return fmt.Sprintf("%#v", []interface{}(key))
+}
+
+// keysCondition filters the rows with any of the keys. Models with a single primary
+// key use a simple IN filter and composite primary keys use tuples:
+//
+//	(a, b) IN ((?, ?), (?, ?))
+func (c *Collection) keysCondition(keys []Key) Condition {
+	pks := c.meta.primaryKeys()
+	if len(pks) == 1 {
+		values := make([]interface{}, len(keys))
+		for i, key := range keys {
+			values[i] = key[0]
+		}
+		return Filter(fmt.Sprintf("%s IN", c.column(pks[0].name)), values)
+	}
+
+	cols := make([]string, len(pks))
+	for i, pk := range pks {
+		cols[i] = c.column(pk.name)
+	}
+
+	placeholders := "(" + strings.TrimSuffix(strings.Repeat("?, ", len(pks)), ", ") + ")"
+	tuples := make([]string, len(keys))
+	var values []interface{}
+	for i, key := range keys {
+		tuples[i] = placeholders
+		values = append(values, key...)
+	}
+
+	return &sqlCondition{
+		sql:    fmt.Sprintf("(%s) IN (%s)", strings.Join(cols, ", "), strings.Join(tuples, ", ")),
+		values: values,
+	}
+}
+
+// DeleteMulti removes the rows with the keys from the collection. Keys should be
+// a slice of Key, or a slice of primary key values if the model has a single one.
+// The filters of the collection are applied too. It does not fail if some of the
+// keys are not found.
+func (c *Collection) DeleteMulti(keys interface{}) error {
+	if c.err != nil {
+		return c.err
+	}
+	if len(c.joins) > 0 {
+		return fmt.Errorf("database: cannot delete models in a collection with joins")
+	}
+
+	list, err := c.meta.keysOf(keys)
+	if err != nil {
+		return err
+	}
+	if len(list) == 0 {
+		return nil
+	}
+
+	b := &sqlBuilder{
+		dialect:    c.dialect,
+		table:      c.model.TableName(),
+		conditions: append(append([]Condition{}, c.conditions...), c.keysCondition(list)),
+	}
+	statement, values := b.DeleteSQL()
+	if c.debug {
+		log.Println("database [DeleteMulti]:", statement)
+	}
+
+	_, err = c.exec(statement, values...)
+	return err
+}
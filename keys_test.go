@@ -0,0 +1,111 @@
+package database
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeysConditionComposite(t *testing.T) {
+	c := newCollection(new(Database), new(testingCompositeModel))
+
+	keys, err := c.meta.keysOf([]Key{NewKey(1, "foo"), NewKey(int64(2), "bar")})
+	require.Nil(t, err)
+
+	cond := c.keysCondition(keys)
+	require.Equal(t, "(`id`, `code`) IN ((?, ?), (?, ?))", cond.SQL())
+	require.Equal(t, []interface{}{int64(1), "foo", int64(2), "bar"}, cond.Values())
+}
+
+func TestKeysConditionSingle(t *testing.T) {
+	c := newCollection(new(Database), new(testingAutoModel))
+
+	keys, err := c.meta.keysOf([]int{3, 4})
+	require.Nil(t, err)
+
+	cond := c.keysCondition(keys)
+	require.Equal(t, "`id` IN (?, ?)", cond.SQL())
+	require.Equal(t, []interface{}{int64(3), int64(4)}, cond.Values())
+}
+
+func TestKeysOfErrors(t *testing.T) {
+	meta := newCollection(new(Database), new(testingCompositeModel)).meta
+
+	_, err := meta.keysOf([]int64{1, 2})
+	require.EqualError(t, err, "database: pass a slice of Key with multiple primary keys")
+
+	_, err = meta.keysOf([]Key{NewKey(1)})
+	require.EqualError(t, err, "database: key (1) has 1 values and the model has 2 primary keys")
+
+	_, err = meta.keysOf([]Key{NewKey("foo", "bar")})
+	require.EqualError(t, err, "database: cannot use foo as the value of the primary key ID")
+
+	_, err = meta.keysOf([]Key{NewKey(1.5, "bar")})
+	require.EqualError(t, err, "database: cannot use 1.5 as the value of the primary key ID")
+}
+
+func TestKeysOfIntegralFloats(t *testing.T) {
+	meta := newCollection(new(Database), new(testingCompositeModel)).meta
+
+	keys, err := meta.keysOf([]Key{NewKey(3.0, "bar")})
+	require.Nil(t, err)
+	require.Equal(t, []Key{{int64(3), "bar"}}, keys)
+}
+
+func TestKeyOf(t *testing.T) {
+	require.Equal(t, Key{int64(3), "foo"}, KeyOf(&testingCompositeModel{ID: 3, Code: "foo", Name: "bar"}))
+}
+
+func TestKeyEncode(t *testing.T) {
+	c := newCollection(new(Database), new(testingCompositeModel))
+
+	key, err := c.DecodeKey(NewKey(int64(9007199254740993), "foo").Encode())
+	require.Nil(t, err)
+	require.Equal(t, Key{int64(9007199254740993), "foo"}, key)
+
+	_, err = c.DecodeKey("invalid")
+	require.Error(t, err)
+}
+
+func TestAutoIncrementProp(t *testing.T) {
+	props := []*Property{
+		{Name: "`id`", Value: int64(0), PrimaryKey: true},
+		{Name: "`code`", Value: "foo", PrimaryKey: true},
+	}
+	require.Equal(t, props[0], autoIncrementProp(props))
+
+	props[0].Value = int64(3)
+	require.Nil(t, autoIncrementProp(props))
+}
+
+func TestGetMultiComposite(t *testing.T) {
+	initDatabase(t)
+	defer closeDatabase()
+
+	first := &testingCompositeModel{Code: "foo", Name: "first"}
+	if testSQLite() {
+		// SQLite only generates the ID of single column INTEGER PRIMARY KEYs.
+		first.ID = 1
+	}
+	require.Nil(t, testingsComposite.Put(first))
+	require.EqualValues(t, 1, first.ID)
+	require.Nil(t, testingsComposite.Put(&testingCompositeModel{ID: 1, Code: "bar", Name: "second"}))
+	require.Nil(t, testingsComposite.Put(&testingCompositeModel{ID: 2, Code: "foo", Name: "third"}))
+
+	var models []*testingCompositeModel
+	err := testingsComposite.GetMulti([]Key{NewKey(2, "foo"), NewKey(1, "baz"), NewKey(1, "bar")}, &models)
+	merr, ok := err.(MultiError)
+	require.True(t, ok)
+	require.Equal(t, MultiError{nil, ErrNoSuchEntity, nil}, merr)
+
+	require.Len(t, models, 3)
+	require.Equal(t, "third", models[0].Name)
+	require.Nil(t, models[1])
+	require.Equal(t, "second", models[2].Name)
+
+	require.Nil(t, testingsComposite.DeleteMulti([]Key{NewKey(1, "foo"), NewKey(2, "foo")}))
+
+	n, err := testingsComposite.Count()
+	require.Nil(t, err)
+	require.EqualValues(t, 1, n)
+}
@@ -0,0 +1,176 @@
+package database
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// metadataCache stores the reflected metadata of every model type the first
+// time it is used, to avoid walking the struct fields again for each query.
+var metadataCache sync.Map
+
+// modelMetadata contains the information of a model type needed to read and
+// write it to the database.
+type modelMetadata struct {
+	fields []*fieldMetadata
+
+	// Relations with other models indexed by the struct field name.
+	relations map[string]*relationMetadata
+}
+
+// fieldMetadata contains the information of a single struct field mapped to
+// a column.
+type fieldMetadata struct {
+	// Escaped name of the column.
+	name string
+
+	// Struct field name.
+	field string
+
+	// Index sequence to reach the field inside the model struct.
+	index []int
+
+	pk, omitEmpty bool
+
+	// Type of the struct field.
+	typ reflect.Type
+
+	// Options to generate the schema of the column.
+	schema columnSchema
+}
+
+// modelMetadataOf returns the cached metadata of a pointer to a model type.
+func modelMetadataOf(t reflect.Type) (*modelMetadata, error) {
+	if meta, ok := metadataCache.Load(t); ok {
+		return meta.(*modelMetadata), nil
+	}
+
+	if t.Kind() != reflect.Ptr || t.Elem().Kind() != reflect.Struct {
+		return nil, fmt.Errorf("database: models should be pointers to structs, got %s", t)
+	}
+
+	meta := &modelMetadata{
+		fields:    []*fieldMetadata{},
+		relations: map[string]*relationMetadata{},
+	}
+	if err := meta.extractStructFields(t.Elem(), nil, "", ""); err != nil {
+		return nil, err
+	}
+
+	cached, _ := metadataCache.LoadOrStore(t, meta)
+	return cached.(*modelMetadata), nil
+}
+
+// props builds the list of properties of the model struct v. All of them are
+// allocated in a single block because it runs for every row we read or write.
+func (meta *modelMetadata) props(v reflect.Value) []*Property {
+	block := make([]Property, len(meta.fields))
+	props := make([]*Property, len(meta.fields))
+	for i, field := range meta.fields {
+		fv := v.FieldByIndex(field.index)
+		block[i] = Property{
+			Name:       field.name,
+			Field:      field.field,
+			Value:      fv.Interface(),
+			Pointer:    fv.Addr().Interface(),
+			PrimaryKey: field.pk,
+			OmitEmpty:  field.omitEmpty,
+			index:      field.index,
+		}
+		props[i] = &block[i]
+	}
+
+	return props
+}
+
+// mappedProps builds the list of properties of the model reading the values
+// and pointers from the generated mapper.
+func (meta *modelMetadata) mappedProps(mapper Mapper) []*Property {
+	values := mapper.DBValues()
+	pointers := mapper.DBPointers()
+
+	block := make([]Property, len(meta.fields))
+	props := make([]*Property, len(meta.fields))
+	for i, field := range meta.fields {
+		block[i] = Property{
+			Name:       field.name,
+			Field:      field.field,
+			Value:      values[i],
+			Pointer:    pointers[i],
+			PrimaryKey: field.pk,
+			OmitEmpty:  field.omitEmpty,
+			index:      field.index,
+		}
+		props[i] = &block[i]
+	}
+
+	return props
+}
+
+// modelProps returns the properties of the model with its current values, using
+// the generated mapper if available.
+func (meta *modelMetadata) modelProps(model Model) []*Property {
+	if mapper, ok := model.(Mapper); ok {
+		return meta.mappedProps(mapper)
+	}
+
+	return meta.props(reflect.ValueOf(model).Elem())
+}
+
+// pointers returns the pointers to each one of the fields of the model struct v,
+// in the same order as the columns. They can be passed directly to Scan.
+func (meta *modelMetadata) pointers(v reflect.Value) []interface{} {
+	ptrs := make([]interface{}, len(meta.fields))
+	for i, field := range meta.fields {
+		ptrs[i] = v.FieldByIndex(field.index).Addr().Interface()
+	}
+
+	return ptrs
+}
+
+// Mapper can be implemented by models to read and write their columns without
+// reflection. It is usually generated with the dbgen command instead of being
+// written by hand. Collections will use it automatically when present.
+type Mapper interface {
+	// DBColumns returns the escaped names of the columns in the same order as
+	// the values and pointers.
+	DBColumns() []string
+
+	// DBValues returns the current value of each column.
+	DBValues() []interface{}
+
+	// DBPointers returns a pointer to the field of each column.
+	DBPointers() []interface{}
+}
+
+// checkMapper verifies the generated mapper of a model, if present, matches
+// the columns read through reflection.
+func (meta *modelMetadata) checkMapper(model Model) error {
+	mapper, ok := model.(Mapper)
+	if !ok {
+		return nil
+	}
+
+	cols := mapper.DBColumns()
+	if len(cols) != len(meta.fields) {
+		return fmt.Errorf("database: generated mapper of %T is outdated, run go generate again", model)
+	}
+	for i, field := range meta.fields {
+		if cols[i] != field.name {
+			return fmt.Errorf("database: generated mapper of %T is outdated, run go generate again", model)
+		}
+	}
+
+	return nil
+}
+
+// scanPointers returns the pointers of each column of the model to pass them
+// to Scan, using the generated mapper if available.
+func (meta *modelMetadata) scanPointers(model Model) []interface{} {
+	if mapper, ok := model.(Mapper); ok {
+		return mapper.DBPointers()
+	}
+
+	return meta.pointers(reflect.ValueOf(model).Elem())
+}
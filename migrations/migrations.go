@@ -0,0 +1,151 @@
+// Package migrations applies versioned changes to the schema of a database.
+//
+// Migrations can be loaded from SQL files in a directory or an embed.FS, or
+// declared as Go functions. SQL files should be named with the version number,
+// a descriptive name and the direction of the change:
+//
+//	0001_create_hotels.up.sql
+//	0001_create_hotels.down.sql
+//	0002_add_hotel_name.up.sql
+//	0002_add_hotel_name.down.sql
+//
+// Files can contain multiple statements separated by semicolons. Triggers and
+// procedures can change the delimiter with DELIMITER lines like in the mysql client.
+//
+// The applied versions are recorded in a bookkeeping table and a MySQL advisory
+// lock ensures that only one replica applies the migrations at the same time.
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+)
+
+var fileRe = regexp.MustCompile(`^(\d+)_([a-zA-Z0-9_\-]+)\.(up|down)\.sql$`)
+
+// Func is a migration step written in Go. It receives the same connection that
+// holds the migrations lock and runs the SQL steps, so any session state like
+// variables or temporary tables is shared with them.
+type Func func(ctx context.Context, conn *sql.Conn) error
+
+// Migration is a single versioned change of the schema.
+type Migration struct {
+	// Version of the migration. Migrations are applied in ascending order.
+	Version int64
+
+	// Name describes the migration.
+	Name string
+
+	// UpSQL and DownSQL contain the statements to apply and revert the migration
+	// when it is loaded from SQL files.
+	UpSQL, DownSQL string
+
+	// UpFunc and DownFunc apply and revert the migration when it is written in Go.
+	UpFunc, DownFunc Func
+}
+
+func (m *Migration) String() string {
+	return fmt.Sprintf("%d_%s", m.Version, m.Name)
+}
+
+// HasDown returns true if the migration can be reverted.
+func (m *Migration) HasDown() bool {
+	return m.DownSQL != "" || m.DownFunc != nil
+}
+
+// NewFunc builds a new migration written in Go. Down can be nil if the migration
+// cannot be reverted.
+func NewFunc(version int64, name string, up, down Func) *Migration {
+	return &Migration{
+		Version:  version,
+		Name:     name,
+		UpFunc:   up,
+		DownFunc: down,
+	}
+}
+
+// FromDir loads the SQL migrations stored in a directory of the filesystem.
+func FromDir(dir string) ([]*Migration, error) {
+	return FromFS(os.DirFS(dir), ".")
+}
+
+// FromFS loads the SQL migrations stored in a directory of fsys. It can be used
+// to load migrations embedded in the binary with embed.FS.
+func FromFS(fsys fs.FS, dir string) ([]*Migration, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, fmt.Errorf("migrations: cannot read directory: %w", err)
+	}
+
+	versions := map[int64]*Migration{}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+
+		matches := fileRe.FindStringSubmatch(entry.Name())
+		if matches == nil {
+			if path.Ext(entry.Name()) == ".sql" {
+				return nil, fmt.Errorf("migrations: invalid file name: %s", entry.Name())
+			}
+			continue
+		}
+
+		version, err := strconv.ParseInt(matches[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("migrations: invalid version in file %s: %w", entry.Name(), err)
+		}
+
+		m, ok := versions[version]
+		if !ok {
+			m = &Migration{
+				Version: version,
+				Name:    matches[2],
+			}
+			versions[version] = m
+		}
+		if m.Name != matches[2] {
+			return nil, fmt.Errorf("migrations: version %d has multiple names: %s and %s", version, m.Name, matches[2])
+		}
+
+		content, err := fs.ReadFile(fsys, path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("migrations: cannot read file: %w", err)
+		}
+		if matches[3] == "up" {
+			m.UpSQL = string(content)
+		} else {
+			m.DownSQL = string(content)
+		}
+	}
+
+	var migrations []*Migration
+	for _, m := range versions {
+		if m.UpSQL == "" {
+			return nil, fmt.Errorf("migrations: missing up file of migration %s", m)
+		}
+		migrations = append(migrations, m)
+	}
+	sortMigrations(migrations)
+
+	return migrations, nil
+}
+
+func sortMigrations(migrations []*Migration) {
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+}
+
+func sortStatuses(statuses []*Status) {
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Migration.Version < statuses[j].Migration.Version
+	})
+}
@@ -0,0 +1,59 @@
+package migrations
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFromFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"migrations/0002_add_name.up.sql":        {Data: []byte("ALTER TABLE hotels ADD name VARCHAR(191);")},
+		"migrations/0002_add_name.down.sql":      {Data: []byte("ALTER TABLE hotels DROP name;")},
+		"migrations/0001_create_hotels.up.sql":   {Data: []byte("CREATE TABLE hotels (id INT);")},
+		"migrations/0001_create_hotels.down.sql": {Data: []byte("DROP TABLE hotels;")},
+		"migrations/README.md":                   {Data: []byte("ignored")},
+	}
+
+	migrations, err := FromFS(fsys, "migrations")
+	require.Nil(t, err)
+
+	require.Len(t, migrations, 2)
+
+	require.EqualValues(t, 1, migrations[0].Version)
+	require.Equal(t, "create_hotels", migrations[0].Name)
+	require.Equal(t, "CREATE TABLE hotels (id INT);", migrations[0].UpSQL)
+	require.Equal(t, "DROP TABLE hotels;", migrations[0].DownSQL)
+
+	require.EqualValues(t, 2, migrations[1].Version)
+	require.Equal(t, "add_name", migrations[1].Name)
+}
+
+func TestFromFSMissingUp(t *testing.T) {
+	fsys := fstest.MapFS{
+		"0001_create_hotels.down.sql": {Data: []byte("DROP TABLE hotels;")},
+	}
+
+	_, err := FromFS(fsys, ".")
+	require.EqualError(t, err, "migrations: missing up file of migration 1_create_hotels")
+}
+
+func TestFromFSInvalidName(t *testing.T) {
+	fsys := fstest.MapFS{
+		"create_hotels.sql": {Data: []byte("CREATE TABLE hotels (id INT);")},
+	}
+
+	_, err := FromFS(fsys, ".")
+	require.EqualError(t, err, "migrations: invalid file name: create_hotels.sql")
+}
+
+func TestFromFSMultipleNames(t *testing.T) {
+	fsys := fstest.MapFS{
+		"0001_create_hotels.up.sql": {Data: []byte("CREATE TABLE hotels (id INT);")},
+		"0001_create_rooms.up.sql":  {Data: []byte("CREATE TABLE rooms (id INT);")},
+	}
+
+	_, err := FromFS(fsys, ".")
+	require.EqualError(t, err, "migrations: version 1 has multiple names: create_hotels and create_rooms")
+}
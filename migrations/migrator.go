@@ -0,0 +1,335 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/altipla-consulting/database"
+)
+
+// Migrator applies and reverts migrations in a database.
+type Migrator struct {
+	db          *database.Database
+	owned       bool
+	migrations  []*Migration
+	table       string
+	lockTimeout time.Duration
+	dryRun      io.Writer
+}
+
+// Option can be passed when creating a new migrator.
+type Option func(m *Migrator)
+
+// WithTable changes the name of the bookkeeping table where the applied versions
+// are recorded. By default it is "schema_migrations".
+func WithTable(table string) Option {
+	return func(m *Migrator) {
+		m.table = table
+	}
+}
+
+// WithLockTimeout changes the maximum time to wait for other replicas to finish
+// their migrations. By default it waits for 1 minute.
+func WithLockTimeout(timeout time.Duration) Option {
+	return func(m *Migrator) {
+		m.lockTimeout = timeout
+	}
+}
+
+// WithDryRun prints the statements that would be executed to w instead of
+// running them. Migrations written in Go won't be called either.
+func WithDryRun(w io.Writer) Option {
+	return func(m *Migrator) {
+		m.dryRun = w
+	}
+}
+
+// New prepares a migrator to apply the list of migrations to the database. Only
+// MySQL databases are supported.
+func New(db *database.Database, migrations []*Migration, options ...Option) (*Migrator, error) {
+	m := &Migrator{
+		db:          db,
+		migrations:  append([]*Migration(nil), migrations...),
+		table:       "schema_migrations",
+		lockTimeout: time.Minute,
+	}
+	for _, option := range options {
+		option(m)
+	}
+
+	sortMigrations(m.migrations)
+	for i, migration := range m.migrations {
+		if migration.UpSQL == "" && migration.UpFunc == nil {
+			return nil, fmt.Errorf("migrations: migration %s has no up step", migration)
+		}
+		if i > 0 && m.migrations[i-1].Version == migration.Version {
+			return nil, fmt.Errorf("migrations: duplicated version %d", migration.Version)
+		}
+	}
+	if db.Dialect() != database.MySQL {
+		return nil, fmt.Errorf("migrations: only MySQL databases are supported")
+	}
+
+	return m, nil
+}
+
+// Open connects to the database with the credentials and prepares a migrator
+// to apply the list of migrations to it. Close the migrator when finished to
+// close the connection too.
+func Open(credentials database.Credentials, migrations []*Migration, options ...Option) (*Migrator, error) {
+	db, err := database.Open(credentials)
+	if err != nil {
+		return nil, err
+	}
+
+	m, err := New(db, migrations, options...)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	m.owned = true
+
+	return m, nil
+}
+
+// Close the connection to the database if the migrator was created with Open.
+func (m *Migrator) Close() {
+	if m.owned {
+		m.db.Close()
+	}
+}
+
+// Status describes the state of a migration in the database.
+type Status struct {
+	Migration *Migration
+
+	// Applied is true if the migration has been applied to the database.
+	Applied bool
+
+	// AppliedAt is the time the migration was applied.
+	AppliedAt time.Time
+
+	// Unknown is true when the version was applied to the database but it is not
+	// in the list of migrations of the migrator.
+	Unknown bool
+}
+
+// Status returns the state of every migration in ascending order of version.
+func (m *Migrator) Status(ctx context.Context) ([]*Status, error) {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	return m.status(ctx, conn)
+}
+
+// Up applies every pending migration in ascending order of version.
+func (m *Migrator) Up(ctx context.Context) error {
+	return m.locked(ctx, func(conn *sql.Conn) error {
+		statuses, err := m.status(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for _, status := range statuses {
+			if status.Applied {
+				continue
+			}
+
+			if err := m.apply(ctx, conn, status.Migration); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// Down reverts the last n applied migrations in descending order of version.
+func (m *Migrator) Down(ctx context.Context, n int) error {
+	return m.locked(ctx, func(conn *sql.Conn) error {
+		statuses, err := m.status(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for i := len(statuses) - 1; i >= 0 && n > 0; i-- {
+			status := statuses[i]
+			if !status.Applied {
+				continue
+			}
+			if status.Unknown {
+				return fmt.Errorf("migrations: cannot revert unknown migration %s", status.Migration)
+			}
+			if !status.Migration.HasDown() {
+				return fmt.Errorf("migrations: migration %s cannot be reverted", status.Migration)
+			}
+
+			if err := m.revert(ctx, conn, status.Migration); err != nil {
+				return err
+			}
+			n--
+		}
+
+		return nil
+	})
+}
+
+// locked runs fn while holding the advisory lock of the migrations. The lock
+// belongs to the connection, so every query should run through it.
+func (m *Migrator) locked(ctx context.Context, fn func(conn *sql.Conn) error) error {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	var acquired sql.NullInt64
+	err = conn.QueryRowContext(ctx, `SELECT GET_LOCK(CONCAT(DATABASE(), '.', ?), ?)`, m.table, int64(m.lockTimeout/time.Second)).Scan(&acquired)
+	if err != nil {
+		return fmt.Errorf("migrations: cannot acquire lock: %w", err)
+	}
+	if acquired.Int64 != 1 {
+		return fmt.Errorf("migrations: timeout waiting for the lock, other replica may be running the migrations")
+	}
+	defer conn.ExecContext(context.Background(), `SELECT RELEASE_LOCK(CONCAT(DATABASE(), '.', ?))`, m.table)
+
+	if m.dryRun == nil {
+		statement := fmt.Sprintf(`
+			CREATE TABLE IF NOT EXISTS %s (
+				version BIGINT NOT NULL,
+				name VARCHAR(191) NOT NULL,
+				applied_at DATETIME NOT NULL,
+
+				PRIMARY KEY(version)
+			) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4
+		`, m.quotedTable())
+		if _, err := conn.ExecContext(ctx, statement); err != nil {
+			return fmt.Errorf("migrations: cannot create bookkeeping table: %w", err)
+		}
+	}
+
+	return fn(conn)
+}
+
+func (m *Migrator) status(ctx context.Context, conn *sql.Conn) ([]*Status, error) {
+	var exists int64
+	err := conn.QueryRowContext(ctx, `SELECT COUNT(*) FROM information_schema.TABLES WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = ?`, m.table).Scan(&exists)
+	if err != nil {
+		return nil, fmt.Errorf("migrations: cannot check bookkeeping table: %w", err)
+	}
+
+	applied := map[int64]*Status{}
+	if exists > 0 {
+		rows, err := conn.QueryContext(ctx, fmt.Sprintf(`SELECT version, name, applied_at FROM %s`, m.quotedTable()))
+		if err != nil {
+			return nil, fmt.Errorf("migrations: cannot read applied versions: %w", err)
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			status := &Status{
+				Migration: new(Migration),
+				Applied:   true,
+				Unknown:   true,
+			}
+			if err := rows.Scan(&status.Migration.Version, &status.Migration.Name, &status.AppliedAt); err != nil {
+				return nil, err
+			}
+			applied[status.Migration.Version] = status
+		}
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+	}
+
+	var statuses []*Status
+	for _, migration := range m.migrations {
+		status, ok := applied[migration.Version]
+		if !ok {
+			status = new(Status)
+		}
+		status.Migration = migration
+		status.Unknown = false
+		delete(applied, migration.Version)
+
+		statuses = append(statuses, status)
+	}
+	for _, status := range applied {
+		statuses = append(statuses, status)
+	}
+	sortStatuses(statuses)
+
+	return statuses, nil
+}
+
+func (m *Migrator) apply(ctx context.Context, conn *sql.Conn, migration *Migration) error {
+	if err := m.run(ctx, conn, migration, "up", migration.UpSQL, migration.UpFunc); err != nil {
+		return err
+	}
+
+	if m.dryRun != nil {
+		return nil
+	}
+	statement := fmt.Sprintf(`INSERT INTO %s(version, name, applied_at) VALUES(?, ?, ?)`, m.quotedTable())
+	if _, err := conn.ExecContext(ctx, statement, migration.Version, migration.Name, time.Now().UTC()); err != nil {
+		return fmt.Errorf("migrations: cannot record migration %s: %w", migration, err)
+	}
+
+	return nil
+}
+
+func (m *Migrator) revert(ctx context.Context, conn *sql.Conn, migration *Migration) error {
+	if err := m.run(ctx, conn, migration, "down", migration.DownSQL, migration.DownFunc); err != nil {
+		return err
+	}
+
+	if m.dryRun != nil {
+		return nil
+	}
+	statement := fmt.Sprintf(`DELETE FROM %s WHERE version = ?`, m.quotedTable())
+	if _, err := conn.ExecContext(ctx, statement, migration.Version); err != nil {
+		return fmt.Errorf("migrations: cannot remove record of migration %s: %w", migration, err)
+	}
+
+	return nil
+}
+
+func (m *Migrator) run(ctx context.Context, conn *sql.Conn, migration *Migration, direction, content string, fn Func) error {
+	if m.dryRun != nil {
+		fmt.Fprintf(m.dryRun, "-- %s (%s)\n", migration, direction)
+		if fn != nil {
+			fmt.Fprintln(m.dryRun, "-- Go function, not executed in dry-run mode")
+		}
+		for _, statement := range splitStatements(content) {
+			fmt.Fprintf(m.dryRun, "%s;\n", statement)
+		}
+		fmt.Fprintln(m.dryRun)
+
+		return nil
+	}
+
+	if fn != nil {
+		if err := fn(ctx, conn); err != nil {
+			return fmt.Errorf("migrations: migration %s (%s) failed: %w", migration, direction, err)
+		}
+		return nil
+	}
+
+	for _, statement := range splitStatements(content) {
+		if _, err := conn.ExecContext(ctx, statement); err != nil {
+			return fmt.Errorf("migrations: migration %s (%s) failed: %w", migration, direction, err)
+		}
+	}
+
+	return nil
+}
+
+func (m *Migrator) quotedTable() string {
+	return fmt.Sprintf("`%s`", m.table)
+}
@@ -0,0 +1,186 @@
+package migrations
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/altipla-consulting/database"
+)
+
+var testDB *database.Database
+
+func initDatabase(t *testing.T) {
+	if os.Getenv("TEST_DIALECT") == "sqlite" {
+		t.Skip("migrations require a MySQL database")
+	}
+
+	var err error
+	testDB, err = database.Open(database.Credentials{
+		User:      "dev-user",
+		Password:  "dev-password",
+		Address:   "localhost:3307",
+		Database:  "test",
+		Charset:   "utf8mb4",
+		Collation: "utf8mb4_bin",
+	}, database.WithDebug(os.Getenv("DEBUG") == "true"))
+	require.Nil(t, err)
+
+	require.Nil(t, testDB.Exec(`DROP TABLE IF EXISTS schema_migrations`))
+	require.Nil(t, testDB.Exec(`DROP TABLE IF EXISTS migrations_hotels`))
+}
+
+func closeDatabase() {
+	testDB.Close()
+}
+
+func testingMigrations() []*Migration {
+	return []*Migration{
+		{
+			Version: 1,
+			Name:    "create_hotels",
+			UpSQL:   "CREATE TABLE migrations_hotels (id INT NOT NULL, PRIMARY KEY(id));",
+			DownSQL: "DROP TABLE migrations_hotels;",
+		},
+		{
+			Version: 2,
+			Name:    "add_name",
+			UpSQL:   "ALTER TABLE migrations_hotels ADD name VARCHAR(191);\nINSERT INTO migrations_hotels(id, name) VALUES (1, 'foo');",
+			DownSQL: "ALTER TABLE migrations_hotels DROP name;",
+		},
+		NewFunc(3, "rename_hotel", func(ctx context.Context, conn *sql.Conn) error {
+			_, err := conn.ExecContext(ctx, `UPDATE migrations_hotels SET name = 'bar' WHERE id = 1`)
+			return err
+		}, nil),
+	}
+}
+
+func TestNewDuplicatedVersion(t *testing.T) {
+	migrations := []*Migration{
+		{Version: 1, Name: "foo", UpSQL: "SELECT 1"},
+		{Version: 1, Name: "bar", UpSQL: "SELECT 1"},
+	}
+
+	_, err := New(nil, migrations)
+	require.EqualError(t, err, "migrations: duplicated version 1")
+}
+
+func TestNewNoUpStep(t *testing.T) {
+	_, err := New(nil, []*Migration{{Version: 1, Name: "foo"}})
+	require.EqualError(t, err, "migrations: migration 1_foo has no up step")
+}
+
+func TestNewOtherDialect(t *testing.T) {
+	db := database.FromDB(nil, database.WithDialect(database.PostgreSQL))
+	_, err := New(db, []*Migration{{Version: 1, Name: "foo", UpSQL: "SELECT 1"}})
+	require.EqualError(t, err, "migrations: only MySQL databases are supported")
+}
+
+func TestUp(t *testing.T) {
+	initDatabase(t)
+	defer closeDatabase()
+
+	m, err := New(testDB, testingMigrations())
+	require.Nil(t, err)
+
+	require.Nil(t, m.Up(context.Background()))
+
+	var name string
+	require.Nil(t, testDB.QueryRow(`SELECT name FROM migrations_hotels WHERE id = 1`).Scan(&name))
+	require.Equal(t, "bar", name)
+
+	statuses, err := m.Status(context.Background())
+	require.Nil(t, err)
+	require.Len(t, statuses, 3)
+	for _, status := range statuses {
+		require.True(t, status.Applied)
+		require.False(t, status.AppliedAt.IsZero())
+	}
+
+	// Running it again should not apply anything.
+	require.Nil(t, m.Up(context.Background()))
+}
+
+func TestDown(t *testing.T) {
+	initDatabase(t)
+	defer closeDatabase()
+
+	m, err := New(testDB, testingMigrations()[:2])
+	require.Nil(t, err)
+
+	require.Nil(t, m.Up(context.Background()))
+	require.Nil(t, m.Down(context.Background(), 1))
+
+	statuses, err := m.Status(context.Background())
+	require.Nil(t, err)
+	require.Len(t, statuses, 2)
+	require.True(t, statuses[0].Applied)
+	require.False(t, statuses[1].Applied)
+
+	var n int64
+	require.Nil(t, testDB.QueryRow(`SELECT COUNT(*) FROM information_schema.COLUMNS WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = 'migrations_hotels' AND COLUMN_NAME = 'name'`).Scan(&n))
+	require.EqualValues(t, 0, n)
+}
+
+func TestDownIrreversible(t *testing.T) {
+	initDatabase(t)
+	defer closeDatabase()
+
+	m, err := New(testDB, testingMigrations())
+	require.Nil(t, err)
+
+	require.Nil(t, m.Up(context.Background()))
+	require.EqualError(t, m.Down(context.Background(), 1), "migrations: migration 3_rename_hotel cannot be reverted")
+}
+
+func TestStatusUnknown(t *testing.T) {
+	initDatabase(t)
+	defer closeDatabase()
+
+	m, err := New(testDB, testingMigrations()[:2])
+	require.Nil(t, err)
+	require.Nil(t, m.Up(context.Background()))
+
+	m, err = New(testDB, testingMigrations()[:1])
+	require.Nil(t, err)
+
+	statuses, err := m.Status(context.Background())
+	require.Nil(t, err)
+	require.Len(t, statuses, 2)
+	require.False(t, statuses[0].Unknown)
+	require.True(t, statuses[1].Unknown)
+	require.Equal(t, "add_name", statuses[1].Migration.Name)
+}
+
+func TestDryRun(t *testing.T) {
+	initDatabase(t)
+	defer closeDatabase()
+
+	var buf bytes.Buffer
+	m, err := New(testDB, testingMigrations(), WithDryRun(&buf))
+	require.Nil(t, err)
+
+	require.Nil(t, m.Up(context.Background()))
+
+	require.Equal(t, `-- 1_create_hotels (up)
+CREATE TABLE migrations_hotels (id INT NOT NULL, PRIMARY KEY(id));
+
+-- 2_add_name (up)
+ALTER TABLE migrations_hotels ADD name VARCHAR(191);
+INSERT INTO migrations_hotels(id, name) VALUES (1, 'foo');
+
+-- 3_rename_hotel (up)
+-- Go function, not executed in dry-run mode
+
+`, buf.String())
+
+	statuses, err := m.Status(context.Background())
+	require.Nil(t, err)
+	for _, status := range statuses {
+		require.False(t, status.Applied)
+	}
+}
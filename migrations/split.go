@@ -0,0 +1,87 @@
+package migrations
+
+import (
+	"regexp"
+	"strings"
+)
+
+var delimiterRe = regexp.MustCompile(`(?i)^[ \t]*DELIMITER[ \t]+(\S+)[ \t]*(\r?\n|$)`)
+
+// splitStatements separates a SQL file in individual statements, because the
+// driver does not accept multiple statements in the same call. Semicolons inside
+// quotes and comments are ignored.
+//
+// Like the mysql client, a DELIMITER line at the start of a statement changes the
+// delimiter to write triggers and procedures with semicolons in their body, and
+// executable comments (/*! ... */) are kept verbatim for the server.
+func splitStatements(content string) []string {
+	var statements []string
+	var current strings.Builder
+	var quote byte
+	delimiter := ";"
+	for i := 0; i < len(content); i++ {
+		ch := content[i]
+
+		switch {
+		case quote != 0:
+			current.WriteByte(ch)
+			if ch == '\\' && quote != '`' && i+1 < len(content) {
+				i++
+				current.WriteByte(content[i])
+			} else if ch == quote {
+				quote = 0
+			}
+
+		case (i == 0 || content[i-1] == '\n') && strings.TrimSpace(current.String()) == "" && delimiterRe.MatchString(content[i:]):
+			matches := delimiterRe.FindStringSubmatch(content[i:])
+			delimiter = matches[1]
+			i += len(matches[0]) - 1
+
+		case strings.HasPrefix(content[i:], delimiter):
+			if statement := strings.TrimSpace(current.String()); statement != "" {
+				statements = append(statements, statement)
+			}
+			current.Reset()
+			i += len(delimiter) - 1
+
+		case ch == '\'' || ch == '"' || ch == '`':
+			quote = ch
+			current.WriteByte(ch)
+
+		case ch == '-' && strings.HasPrefix(content[i:], "-- "), ch == '#':
+			end := strings.IndexByte(content[i:], '\n')
+			if end == -1 {
+				i = len(content)
+			} else {
+				i += end
+				current.WriteByte('\n')
+			}
+
+		case ch == '/' && strings.HasPrefix(content[i:], "/*!"):
+			end := strings.Index(content[i+3:], "*/")
+			if end == -1 {
+				current.WriteString(content[i:])
+				i = len(content)
+			} else {
+				current.WriteString(content[i : i+3+end+2])
+				i += 3 + end + 1
+			}
+
+		case ch == '/' && strings.HasPrefix(content[i:], "/*"):
+			end := strings.Index(content[i+2:], "*/")
+			if end == -1 {
+				i = len(content)
+			} else {
+				i += end + 3
+			}
+
+		default:
+			current.WriteByte(ch)
+		}
+	}
+	if statement := strings.TrimSpace(current.String()); statement != "" {
+		statements = append(statements, statement)
+	}
+
+	return statements
+}
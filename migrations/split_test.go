@@ -0,0 +1,65 @@
+package migrations
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitStatements(t *testing.T) {
+	content := `
+-- Create the table; with a comment.
+CREATE TABLE hotels (
+  id INT,
+  name VARCHAR(191) DEFAULT 'foo;bar'
+);
+
+/* Block comment; ignored. */
+INSERT INTO hotels(id, name) VALUES (1, "it\"s;");
+UPDATE hotels SET name = 'baz' WHERE id = 1
+`
+
+	require.Equal(t, []string{
+		"CREATE TABLE hotels (\n  id INT,\n  name VARCHAR(191) DEFAULT 'foo;bar'\n)",
+		`INSERT INTO hotels(id, name) VALUES (1, "it\"s;")`,
+		"UPDATE hotels SET name = 'baz' WHERE id = 1",
+	}, splitStatements(content))
+}
+
+func TestSplitStatementsEmpty(t *testing.T) {
+	require.Empty(t, splitStatements("\n-- Nothing to run.\n;\n"))
+}
+
+func TestSplitStatementsExecutableComments(t *testing.T) {
+	content := `
+/*!40101 SET @OLD_CHARACTER_SET_CLIENT=@@CHARACTER_SET_CLIENT */;
+/* Ignored; comment. */
+/*!40014 SET FOREIGN_KEY_CHECKS=0; */
+`
+
+	require.Equal(t, []string{
+		"/*!40101 SET @OLD_CHARACTER_SET_CLIENT=@@CHARACTER_SET_CLIENT */",
+		"/*!40014 SET FOREIGN_KEY_CHECKS=0; */",
+	}, splitStatements(content))
+}
+
+func TestSplitStatementsDelimiter(t *testing.T) {
+	content := `
+CREATE TABLE hotels (id INT);
+
+DELIMITER $$
+CREATE TRIGGER hotels_insert BEFORE INSERT ON hotels FOR EACH ROW
+BEGIN
+  SET NEW.id = NEW.id + 1;
+END$$
+delimiter ;
+
+INSERT INTO hotels(id) VALUES (1);
+`
+
+	require.Equal(t, []string{
+		"CREATE TABLE hotels (id INT)",
+		"CREATE TRIGGER hotels_insert BEFORE INSERT ON hotels FOR EACH ROW\nBEGIN\n  SET NEW.id = NEW.id + 1;\nEND",
+		"INSERT INTO hotels(id) VALUES (1)",
+	}, splitStatements(content))
+}
@@ -1,13 +1,22 @@
 package database
 
 import (
+	"database/sql"
+	"database/sql/driver"
 	"fmt"
 	"reflect"
+	"strconv"
 	"strings"
+	"time"
 	"unicode"
 )
 
-var modelTrackingType = reflect.TypeOf(ModelTracking{})
+var (
+	modelTrackingType = reflect.TypeOf(ModelTracking{})
+	scannerType       = reflect.TypeOf((*sql.Scanner)(nil)).Elem()
+	valuerType        = reflect.TypeOf((*driver.Valuer)(nil)).Elem()
+	timeType          = reflect.TypeOf(time.Time{})
+)
 
 // OnAfterPutHooker can be implemented by any model to receive a call every time
 // the model is saved to the database.
@@ -84,7 +93,8 @@ type Property struct {
 	// Name of the column. Already escaped.
 	Name string
 
-	// Struct field name.
+	// Struct field name. Fields of named inline structs are prefixed with the
+	// name of the parent field separated by a dot, e.g. "Billing.Street".
 	Field string
 
 	// Value of the field.
@@ -98,76 +108,205 @@ type Property struct {
 
 	// Omit the column when the value is empty.
 	OmitEmpty bool
+
+	// Index sequence of the field inside the model struct, including the
+	// embedded structs it is inlined from.
+	index []int
 }
 
 func extractModelProps(model Model) ([]*Property, error) {
-	v := reflect.ValueOf(model).Elem()
-	t := reflect.TypeOf(model).Elem()
+	meta, err := modelMetadataOf(reflect.TypeOf(model))
+	if err != nil {
+		return nil, err
+	}
+
+	return meta.props(reflect.ValueOf(model).Elem()), nil
+}
 
-	props := []*Property{}
+func (meta *modelMetadata) extractStructFields(t reflect.Type, index []int, prefix, fieldPrefix string) error {
 	for i := 0; i < t.NumField(); i++ {
-		fv := v.Field(i)
 		ft := t.Field(i)
 
 		if !startsWithUppercase(ft.Name) {
 			continue
 		}
 
+		fieldIndex := make([]int, len(index)+1)
+		copy(fieldIndex, index)
+		fieldIndex[len(index)] = i
+
 		if ft.Type == modelTrackingType {
-			props = append(props, &Property{
-				Name:    "`revision`",
-				Field:   "Revision",
-				Value:   fv.FieldByName("Revision").Interface(),
-				Pointer: fv.FieldByName("Revision").Addr().Interface(),
+			sf, _ := modelTrackingType.FieldByName("Revision")
+			meta.fields = append(meta.fields, &fieldMetadata{
+				name:  "`revision`",
+				field: "Revision",
+				index: append(fieldIndex, sf.Index...),
+				typ:   sf.Type,
 			})
 
 			continue
 		}
 
-		prop := &Property{
-			Name:    ft.Name,
-			Field:   ft.Name,
-			Value:   fv.Interface(),
-			Pointer: fv.Addr().Interface(),
+		tag, err := parseTag(ft.Tag.Get("db"))
+		if err != nil {
+			return err
+		}
+		if tag.relation != nil {
+			if tag.name != "-" {
+				return fmt.Errorf("database: relation %s should be tagged with the column name \"-\"", ft.Name)
+			}
+			if err := meta.addRelation(ft, fieldIndex, fieldPrefix, tag.relation); err != nil {
+				return err
+			}
+			continue
+		}
+		if tag.name == "-" {
+			continue
 		}
 
-		tag := ft.Tag.Get("db")
-		if tag != "" {
-			parts := strings.Split(tag, ",")
-			if len(parts) > 2 {
-				return nil, fmt.Errorf("database: unknown struct tag: %s", parts[1])
+		if ft.Anonymous && tag.name == "" && isEmbeddable(ft.Type) {
+			tag.inline = true
+		}
+		if tag.inline {
+			if !isEmbeddable(ft.Type) {
+				return fmt.Errorf("database: cannot inline field %s: it is not a struct", ft.Name)
 			}
 
-			if parts[0] != "" {
-				prop.Name = parts[0]
+			field := fieldPrefix
+			if !ft.Anonymous {
+				field += ft.Name + "."
 			}
 
-			if len(parts) > 1 {
-				switch parts[1] {
-				case "pk":
-					prop.PrimaryKey = true
-					prop.OmitEmpty = true
+			if err := meta.extractStructFields(ft.Type, fieldIndex, prefix+tag.prefix, field); err != nil {
+				return err
+			}
 
-				case "omitempty":
-					prop.OmitEmpty = true
+			continue
+		}
+		if tag.prefix != "" {
+			return fmt.Errorf("database: prefix can only be used with inline fields: %s", ft.Name)
+		}
 
-				default:
-					return nil, fmt.Errorf("database: unknown struct tag: %s", parts[1])
-				}
-			}
+		name := ft.Name
+		if tag.name != "" {
+			name = tag.name
 		}
 
-		if prop.Name == "-" {
-			continue
+		meta.fields = append(meta.fields, &fieldMetadata{
+			// Escape the name inside the SQL query. It is NOT for security.
+			name:      fmt.Sprintf("`%s%s`", prefix, name),
+			field:     fieldPrefix + ft.Name,
+			index:     fieldIndex,
+			pk:        tag.pk,
+			omitEmpty: tag.omitEmpty,
+			typ:       ft.Type,
+			schema:    tag.schema,
+		})
+	}
+
+	return nil
+}
+
+type structTag struct {
+	name      string
+	pk        bool
+	omitEmpty bool
+	inline    bool
+	prefix    string
+	schema    columnSchema
+	relation  *relationMetadata
+}
+
+func parseTag(tag string) (structTag, error) {
+	if tag == "" {
+		return structTag{}, nil
+	}
+
+	parts := strings.Split(tag, ",")
+	st := structTag{
+		name: parts[0],
+	}
+	for _, part := range parts[1:] {
+		switch {
+		case part == "pk":
+			st.pk = true
+			st.omitEmpty = true
+
+		case part == "omitempty":
+			st.omitEmpty = true
+
+		case part == "inline":
+			st.inline = true
+
+		case strings.HasPrefix(part, "prefix="):
+			st.prefix = strings.TrimPrefix(part, "prefix=")
+
+		case strings.HasPrefix(part, "size="):
+			size, err := strconv.ParseInt(strings.TrimPrefix(part, "size="), 10, 64)
+			if err != nil || size <= 0 {
+				return structTag{}, fmt.Errorf("database: invalid size in struct tag: %s", part)
+			}
+			st.schema.size = size
+
+		case part == "index":
+			st.schema.index = true
+
+		case strings.HasPrefix(part, "index="):
+			st.schema.index = true
+			st.schema.indexName = strings.TrimPrefix(part, "index=")
+			if !aliasRe.MatchString(st.schema.indexName) {
+				return structTag{}, fmt.Errorf("database: invalid index name in struct tag: %s", part)
+			}
+
+		case part == "unique":
+			st.schema.unique = true
+
+		case strings.HasPrefix(part, "unique="):
+			st.schema.unique = true
+			st.schema.uniqueName = strings.TrimPrefix(part, "unique=")
+			if !aliasRe.MatchString(st.schema.uniqueName) {
+				return structTag{}, fmt.Errorf("database: invalid index name in struct tag: %s", part)
+			}
+
+		case strings.HasPrefix(part, "default="):
+			st.schema.hasDefault = true
+			st.schema.defaultValue = strings.TrimPrefix(part, "default=")
+
+		case strings.HasPrefix(part, "charset="):
+			st.schema.charset = strings.TrimPrefix(part, "charset=")
+			if !collationRe.MatchString(st.schema.charset) {
+				return structTag{}, fmt.Errorf("database: invalid charset in struct tag: %s", part)
+			}
+
+		case strings.HasPrefix(part, "belongsto="):
+			st.relation = &relationMetadata{
+				kind: belongsTo,
+				key:  strings.TrimPrefix(part, "belongsto="),
+			}
+
+		case strings.HasPrefix(part, "hasmany="):
+			st.relation = &relationMetadata{
+				kind: hasMany,
+				key:  strings.TrimPrefix(part, "hasmany="),
+			}
+
+		default:
+			return structTag{}, fmt.Errorf("database: unknown struct tag: %s", part)
 		}
+	}
 
-		// Escape the name inside the SQL query. It is NOT for security.
-		prop.Name = fmt.Sprintf("`%s`", prop.Name)
+	return st, nil
+}
 
-		props = append(props, prop)
+// isEmbeddable returns true if the type is a struct that should be flattened
+// instead of being scanned as a single column.
+func isEmbeddable(t reflect.Type) bool {
+	if t.Kind() != reflect.Struct {
+		return false
 	}
 
-	return props, nil
+	ptr := reflect.PointerTo(t)
+	return !ptr.Implements(scannerType) && !ptr.Implements(valuerType) && t != timeType
 }
 
 func isZero(value interface{}) bool {
@@ -185,24 +324,6 @@ func isZero(value interface{}) bool {
 	return false
 }
 
-func updatedProps(props []*Property, model Model) []*Property {
-	v := reflect.ValueOf(model).Elem()
-
-	var result []*Property
-	for _, prop := range props {
-		result = append(result, &Property{
-			Name:       prop.Name,
-			Field:      prop.Field,
-			Value:      v.FieldByName(prop.Field).Interface(),
-			Pointer:    v.FieldByName(prop.Field).Addr().Interface(),
-			PrimaryKey: prop.PrimaryKey,
-			OmitEmpty:  prop.OmitEmpty,
-		})
-	}
-
-	return result
-}
-
 func startsWithUppercase(s string) bool {
 	for _, r := range s {
 		return unicode.IsUpper(r)
@@ -1,7 +1,11 @@
 package database
 
 import (
+	"database/sql"
+	"database/sql/driver"
+	"reflect"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 )
@@ -80,3 +84,124 @@ func TestIncrementRevisionAfterPut(t *testing.T) {
 	require.EqualValues(t, 0, tracking.StoredRevision())
 	require.EqualValues(t, 1, tracking.Revision)
 }
+
+func TestExtractModelPropsEmbedded(t *testing.T) {
+	props, err := extractModelProps(new(testingEmbeddedModel))
+	require.Nil(t, err)
+
+	var names, fields []string
+	for _, prop := range props {
+		names = append(names, prop.Name)
+		fields = append(fields, prop.Field)
+	}
+	require.Equal(t, []string{"`revision`", "`updated_by`", "`id`", "`billing_street`", "`billing_city`"}, names)
+	require.Equal(t, []string{"Revision", "UpdatedBy", "ID", "Billing.Street", "Billing.City"}, fields)
+}
+
+func TestModelPropsEmbedded(t *testing.T) {
+	meta, err := modelMetadataOf(reflect.TypeOf(new(testingEmbeddedModel)))
+	require.Nil(t, err)
+
+	m := &testingEmbeddedModel{
+		ID: 3,
+		Billing: testingAddress{
+			Street: "foo",
+		},
+	}
+	m.UpdatedBy = "bar"
+	updated := meta.modelProps(m)
+
+	require.Equal(t, "bar", updated[1].Value)
+	require.EqualValues(t, 3, updated[2].Value)
+	require.Equal(t, "foo", updated[3].Value)
+
+	*updated[4].Pointer.(*string) = "baz"
+	require.Equal(t, "baz", m.Billing.City)
+}
+
+// TestingMoney should be exported to be embedded in the models.
+type TestingMoney struct {
+	Cents int64
+}
+
+func (money TestingMoney) Value() (driver.Value, error) {
+	return money.Cents, nil
+}
+
+func TestExtractModelPropsEmbeddedScalars(t *testing.T) {
+	type model struct {
+		testingModel
+		time.Time
+		sql.NullString
+		TestingMoney
+	}
+
+	props, err := extractModelProps(new(model))
+	require.Nil(t, err)
+
+	var names []string
+	for _, prop := range props {
+		names = append(names, prop.Name)
+	}
+	require.Equal(t, []string{"`Time`", "`NullString`", "`TestingMoney`"}, names)
+}
+
+func TestExtractModelPropsPrefixRequiresInline(t *testing.T) {
+	type model struct {
+		testingModel
+
+		Billing testingAddress `db:",prefix=billing_"`
+	}
+
+	_, err := extractModelProps(new(model))
+	require.EqualError(t, err, "database: prefix can only be used with inline fields: Billing")
+}
+
+func BenchmarkExtractModelProps(b *testing.B) {
+	m := new(testingEmbeddedModel)
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := extractModelProps(m); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkModelProps(b *testing.B) {
+	m := new(testingEmbeddedModel)
+	meta, err := modelMetadataOf(reflect.TypeOf(m))
+	require.Nil(b, err)
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		meta.modelProps(m)
+	}
+}
+
+func BenchmarkScanPointers(b *testing.B) {
+	m := new(testingEmbeddedModel)
+	meta, err := modelMetadataOf(reflect.TypeOf(m))
+	require.Nil(b, err)
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		meta.pointers(reflect.ValueOf(m).Elem())
+	}
+}
+
+type testingOutdatedMapperModel struct {
+	testingMapperModel
+
+	Extra string `db:"extra"`
+}
+
+func TestOutdatedMapperPanics(t *testing.T) {
+	defer func() {
+		err, ok := recover().(error)
+		require.True(t, ok)
+		require.EqualError(t, err, "database: generated mapper of *database.testingOutdatedMapperModel is outdated, run go generate again")
+	}()
+
+	newCollection(new(Database), new(testingOutdatedMapperModel))
+}
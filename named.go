@@ -0,0 +1,182 @@
+package database
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Named contains the values of the named parameters (":name") of a raw SQL query.
+// Pass it as the only parameter of Exec, Query or Raw, or to QueryRowNamed, to use
+// them instead of positional "?" placeholders:
+//
+//	db.Exec(`UPDATE hotels SET name = :name WHERE id = :id`, database.Named{
+//	  "id":   3,
+//	  "name": "foo",
+//	})
+type Named map[string]interface{}
+
+// bindNamed rewrites the named parameters of the query (":name") to positional
+// placeholders and returns the values in the same order. Slices are expanded to
+// a list of placeholders to use them with IN. Empty slices return an error: there
+// is no list that works both with IN and NOT IN, check them before building the
+// query. Parameters inside quoted strings, identifiers and comments, and the
+// assignment operator ":=" are not replaced. The dialect decides how the quotes
+// are escaped inside strings.
+func bindNamed(dialect Dialect, query string, params Named) (string, []interface{}, error) {
+	var sql strings.Builder
+	var values []interface{}
+
+	backslashEscapes := usesBackslashEscapes(dialect)
+	for i := 0; i < len(query); i++ {
+		if end := skipLiteral(query, i, backslashEscapes); end > i {
+			sql.WriteString(query[i:end])
+			i = end - 1
+			continue
+		}
+
+		ch := query[i]
+		switch {
+		case ch == ':' && i+1 < len(query) && query[i+1] == ':':
+			sql.WriteString("::")
+			i++
+			continue
+
+		case ch == ':' && i+1 < len(query) && isNameStart(query[i+1]):
+			end := i + 1
+			for end < len(query) && isNamePart(query[end]) {
+				end++
+			}
+			name := query[i+1 : end]
+
+			value, ok := params[name]
+			if !ok {
+				return "", nil, fmt.Errorf("database: missing named parameter %s", name)
+			}
+			placeholders, expanded := expandParam(value)
+			if placeholders == "" {
+				return "", nil, fmt.Errorf("database: named parameter %s is an empty list", name)
+			}
+			sql.WriteString(placeholders)
+			values = append(values, expanded...)
+
+			i = end - 1
+			continue
+		}
+
+		sql.WriteByte(ch)
+	}
+
+	return sql.String(), values, nil
+}
+
+// skipLiteral returns the end of the quoted string, quoted identifier or comment
+// that starts at position i of the query, or i itself if there is none. Quotes
+// are escaped doubling them. With backslashEscapes, like in MySQL, they can be
+// escaped with a backslash inside strings too; otherwise only inside E'...'
+// strings, like in PostgreSQL.
+func skipLiteral(query string, i int, backslashEscapes bool) int {
+	switch ch := query[i]; {
+	case ch == '\'' || ch == '"' || ch == '`':
+		escapes := ch != '`' && (backslashEscapes || isEscapeString(query, i))
+		for end := i + 1; end < len(query); end++ {
+			switch query[end] {
+			case '\\':
+				if escapes {
+					end++
+				}
+			case ch:
+				return end + 1
+			}
+		}
+		return len(query)
+
+	case strings.HasPrefix(query[i:], "--"):
+		if end := strings.IndexByte(query[i:], '\n'); end != -1 {
+			return i + end + 1
+		}
+		return len(query)
+
+	case strings.HasPrefix(query[i:], "/*"):
+		if end := strings.Index(query[i+2:], "*/"); end != -1 {
+			return i + 2 + end + 2
+		}
+		return len(query)
+	}
+
+	return i
+}
+
+// isEscapeString reports if the quote at position i of the query opens an escape
+// string constant of PostgreSQL, like E'C:\\'.
+func isEscapeString(query string, i int) bool {
+	if query[i] != '\'' || i == 0 || (query[i-1] != 'E' && query[i-1] != 'e') {
+		return false
+	}
+
+	return i == 1 || !isNamePart(query[i-2])
+}
+
+// expandParam returns the placeholders and values of a named parameter. Empty
+// slices return no placeholders.
+func expandParam(value interface{}) (string, []interface{}) {
+	v := reflect.ValueOf(value)
+	if v.Kind() != reflect.Slice || v.Type() == bytesType {
+		return "?", []interface{}{value}
+	}
+
+	placeholders := make([]string, v.Len())
+	values := make([]interface{}, v.Len())
+	for i := range placeholders {
+		placeholders[i] = "?"
+		values[i] = v.Index(i).Interface()
+	}
+
+	return strings.Join(placeholders, ", "), values
+}
+
+func isNameStart(ch byte) bool {
+	return ch == '_' || (ch >= 'a' && ch <= 'z') || (ch >= 'A' && ch <= 'Z')
+}
+
+func isNamePart(ch byte) bool {
+	return isNameStart(ch) || (ch >= '0' && ch <= '9')
+}
+
+// bindParams rewrites the named parameters of the query if the only parameter
+// is a Named value with their values. Otherwise it returns the positional
+// parameters as they are.
+func bindParams(dialect Dialect, query string, params []interface{}) (string, []interface{}, error) {
+	if len(params) != 1 {
+		return query, params, nil
+	}
+	named, ok := params[0].(Named)
+	if !ok {
+		return query, params, nil
+	}
+
+	return bindNamed(dialect, query, named)
+}
+
+// FilterExpr creates a new condition with a custom SQL expression that uses
+// named parameters. Slices are expanded to use them with IN:
+//
+//	FilterExpr("created_at BETWEEN :from AND :to AND status IN (:statuses)", database.Named{
+//	  "from":     from,
+//	  "to":       to,
+//	  "statuses": []string{"pending", "confirmed"},
+//	})
+//
+// It panics if any of the parameters of the expression is missing in the map or
+// is an empty list; Collection.FilterExpr returns the error when the query runs
+// instead. Strings in the expression follow the MySQL rules, with backslash
+// escapes; use Collection.FilterExpr with other dialects. The expression is not
+// validated, never build it with user input.
+func FilterExpr(sql string, params Named) Condition {
+	sql, values, err := bindNamed(MySQL, sql, params)
+	if err != nil {
+		panic(err.Error())
+	}
+
+	return &sqlCondition{sql, values}
+}
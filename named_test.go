@@ -0,0 +1,132 @@
+package database
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBindNamed(t *testing.T) {
+	tests := []struct {
+		query  string
+		params Named
+		sql    string
+		values []interface{}
+	}{
+		{
+			query:  "SELECT * FROM foo WHERE a > :from AND a < :to",
+			params: Named{"from": 1, "to": 2},
+			sql:    "SELECT * FROM foo WHERE a > ? AND a < ?",
+			values: []interface{}{1, 2},
+		},
+		{
+			query:  "a = :foo OR b = :foo",
+			params: Named{"foo": "bar"},
+			sql:    "a = ? OR b = ?",
+			values: []interface{}{"bar", "bar"},
+		},
+		{
+			query:  "id IN (:ids) AND data = :data",
+			params: Named{"ids": []int64{3, 4, 5}, "data": []byte("foo")},
+			sql:    "id IN (?, ?, ?) AND data = ?",
+			values: []interface{}{int64(3), int64(4), int64(5), []byte("foo")},
+		},
+		{
+			query:  `name = ':foo' AND other = "it\":s" AND @a := 3 AND b::text = :b_2`,
+			params: Named{"b_2": 4},
+			sql:    `name = ':foo' AND other = "it\":s" AND @a := 3 AND b::text = ?`,
+			values: []interface{}{4},
+		},
+		{
+			query:  "a = :a -- it's :b\n/* :c ' */ AND `:d` = 'it\\':s' AND b = :b",
+			params: Named{"a": 1, "b": 2},
+			sql:    "a = ? -- it's :b\n/* :c ' */ AND `:d` = 'it\\':s' AND b = ?",
+			values: []interface{}{1, 2},
+		},
+	}
+	for _, test := range tests {
+		sql, values, err := bindNamed(MySQL, test.query, test.params)
+		require.Nil(t, err, test.query)
+		require.Equal(t, test.sql, sql, test.query)
+		require.Equal(t, test.values, values, test.query)
+	}
+}
+
+func TestBindNamedStandardStrings(t *testing.T) {
+	sql, values, err := bindNamed(PostgreSQL, `path = 'C:\' AND id = :id AND name = E'it\':s'`, Named{"id": 3})
+	require.Nil(t, err)
+	require.Equal(t, `path = 'C:\' AND id = ? AND name = E'it\':s'`, sql)
+	require.Equal(t, []interface{}{3}, values)
+
+	sql, _, err = bindNamed(SQLite, `path = 'C:\' AND id = :id`, Named{"id": 3})
+	require.Nil(t, err)
+	require.Equal(t, `path = 'C:\' AND id = ?`, sql)
+}
+
+func TestBindNamedMissing(t *testing.T) {
+	_, _, err := bindNamed(MySQL, "a = :foo", Named{"bar": 3})
+	require.EqualError(t, err, "database: missing named parameter foo")
+}
+
+func TestBindNamedEmptyList(t *testing.T) {
+	_, _, err := bindNamed(MySQL, "id NOT IN (:ids)", Named{"ids": []int64{}})
+	require.EqualError(t, err, "database: named parameter ids is an empty list")
+}
+
+func TestBindParamsMap(t *testing.T) {
+	params := []interface{}{map[string]interface{}{"foo": 3}}
+	sql, values, err := bindParams(MySQL, "a = :foo", params)
+	require.Nil(t, err)
+	require.Equal(t, "a = :foo", sql)
+	require.Equal(t, params, values)
+}
+
+func TestQueryRowNamedMissing(t *testing.T) {
+	_, err := new(Database).QueryRowNamed("SELECT a FROM foo WHERE a = :foo", Named{})
+	require.EqualError(t, err, "database: missing named parameter foo")
+}
+
+func TestBindParamsPositional(t *testing.T) {
+	sql, values, err := bindParams(MySQL, "a = ? AND b = :foo", []interface{}{3})
+	require.Nil(t, err)
+	require.Equal(t, "a = ? AND b = :foo", sql)
+	require.Equal(t, []interface{}{3}, values)
+}
+
+func TestFilterExpr(t *testing.T) {
+	cond := FilterExpr("DATEDIFF(:now, created_at) > :days", Named{"now": "2018-01-01", "days": 30})
+	require.Equal(t, "DATEDIFF(?, created_at) > ?", cond.SQL())
+	require.Equal(t, []interface{}{"2018-01-01", 30}, cond.Values())
+
+	require.PanicsWithValue(t, "database: missing named parameter days", func() {
+		FilterExpr("created_at > :days", nil)
+	})
+}
+
+func TestCollectionFilterExprError(t *testing.T) {
+	c := newCollection(new(Database), new(testingModel)).FilterExpr("code NOT IN (:codes)", Named{"codes": []string{}})
+	require.EqualError(t, c.Err(), "database: named parameter codes is an empty list")
+	require.Empty(t, c.conditions)
+}
+
+func TestNamedParameters(t *testing.T) {
+	initDatabase(t)
+	defer closeDatabase()
+
+	require.Nil(t, testDB.Exec(`INSERT INTO testing (code, name, revision) VALUES (:code, :name, 0)`, Named{
+		"code": "foo",
+		"name": "foo name",
+	}))
+	require.Nil(t, testings.Put(&testingModel{Code: "bar", Name: "bar name"}))
+
+	row, err := testDB.QueryRowNamed(`SELECT name FROM testing WHERE code = :code`, Named{"code": "foo"})
+	require.Nil(t, err)
+	var name string
+	require.Nil(t, row.Scan(&name))
+	require.Equal(t, "foo name", name)
+
+	var models []*testingModel
+	require.Nil(t, testings.Clone().FilterExpr("code IN (:codes)", Named{"codes": []string{"foo", "bar"}}).Order("code").GetAll(&models))
+	require.Len(t, models, 2)
+	require.Equal(t, "bar", models[0].Code)
+}
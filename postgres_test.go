@@ -0,0 +1,94 @@
+package database
+
+import (
+	"os"
+	"testing"
+
+	_ "github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+)
+
+// initPostgreSQL opens the PostgreSQL server of docker-compose with the tables
+// of testingModel and testingAutoModel.
+func initPostgreSQL(t *testing.T) *Database {
+	skipSQLite(t)
+
+	db, err := Open(Credentials{
+		User:     "dev-user",
+		Password: "dev-password",
+		Address:  "localhost:5433",
+		Database: "test",
+	}, WithDialect(PostgreSQL), WithDebug(os.Getenv("DEBUG") == "true"))
+	require.Nil(t, err)
+
+	require.Nil(t, db.Exec(`DROP TABLE IF EXISTS testing`))
+	require.Nil(t, db.Exec(`
+    CREATE TABLE testing (
+      code VARCHAR(191) PRIMARY KEY,
+      name VARCHAR(191),
+      revision INTEGER NOT NULL
+    )
+  `))
+
+	require.Nil(t, db.Exec(`DROP TABLE IF EXISTS testing_auto`))
+	require.Nil(t, db.Exec(`
+    CREATE TABLE testing_auto (
+      id SERIAL PRIMARY KEY,
+      name VARCHAR(191),
+      revision INTEGER NOT NULL
+    )
+  `))
+
+	return db
+}
+
+func TestPostgreSQLCollection(t *testing.T) {
+	db := initPostgreSQL(t)
+	defer db.Close()
+
+	autos := db.Collection(new(testingAutoModel))
+	for _, name := range []string{"foo", "bar", "baz"} {
+		require.Nil(t, autos.Put(&testingAutoModel{Name: name}))
+	}
+
+	model := &testingAutoModel{ID: 2}
+	require.Nil(t, autos.Get(model))
+	require.EqualValues(t, 2, model.ID)
+	require.Equal(t, "bar", model.Name)
+
+	model.Name = "qux"
+	require.Nil(t, autos.Put(model))
+
+	var models []*testingAutoModel
+	require.Nil(t, autos.Clone().Order("-id").Offset(1).Limit(2).GetAll(&models))
+	require.Len(t, models, 2)
+	require.Equal(t, "qux", models[0].Name)
+	require.Equal(t, "foo", models[1].Name)
+
+	require.Nil(t, autos.Delete(model))
+	n, err := autos.Count()
+	require.Nil(t, err)
+	require.EqualValues(t, 2, n)
+
+	require.Nil(t, autos.Truncate())
+	model = &testingAutoModel{Name: "foo"}
+	require.Nil(t, autos.Put(model))
+	require.EqualValues(t, 1, model.ID)
+}
+
+func TestPostgreSQLBackslashLiterals(t *testing.T) {
+	db := initPostgreSQL(t)
+	defer db.Close()
+
+	require.Nil(t, db.Exec(`INSERT INTO testing(code, name, revision) VALUES('foo', 'C:\', ?)`, 0))
+	require.Nil(t, db.Exec(`INSERT INTO testing(code, name, revision) VALUES(:code, E'D:\\', 0)`, Named{"code": "bar"}))
+
+	var name string
+	require.Nil(t, db.QueryRow(`SELECT name FROM testing WHERE name = 'C:\' AND code = ?`, "foo").Scan(&name))
+	require.Equal(t, `C:\`, name)
+
+	var models []*testingModel
+	require.Nil(t, db.Collection(new(testingModel)).FilterExpr(`name = 'D:\' AND code = :code`, Named{"code": "bar"}).GetAll(&models))
+	require.Len(t, models, 1)
+	require.Equal(t, `D:\`, models[0].Name)
+}
@@ -0,0 +1,191 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"reflect"
+)
+
+// Query runs a raw SQL query and scans the result in dest, that can be a pointer
+// to a slice of structs or pointers to structs, or a pointer to a single struct to
+// read only the first row. Columns are matched by name with the db tags of the struct
+// fields, like in models. Columns without a field and fields without a column are
+// ignored. If dest is a single struct and there are no rows ErrNoSuchEntity is returned.
+//
+//	type Report struct {
+//	  HotelID int64 `db:"hotel_id"`
+//	  Total   int64 `db:"total"`
+//	}
+//	var reports []*Report
+//	err := db.Query(&reports, `SELECT hotel_id, COUNT(*) AS total FROM rooms GROUP BY hotel_id`)
+//
+// Models read with Query can be updated later with Put if the result contains their
+// primary keys and revision column. It accepts named parameters like Exec.
+func (db *Database) Query(dest interface{}, query string, args ...interface{}) error {
+	query, args, err := bindParams(dialectOf(db), query, args)
+	if err != nil {
+		return err
+	}
+	if db.debug {
+		log.Println("database [Query]:", query)
+	}
+
+	rows, err := db.sess.Query(dialectOf(db).Rebind(query), args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	return scanRaw(rows, dest)
+}
+
+// RawQuery is a raw SQL query that reads models of a collection.
+type RawQuery struct {
+	c     *Collection
+	query string
+	args  []interface{}
+}
+
+// Raw prepares a raw SQL query to read models of the collection. Filters, orders
+// and the rest of the configuration of the collection are not applied to it. See
+// Database.Query for the rules to match the columns and the parameters.
+func (c *Collection) Raw(query string, args ...interface{}) *RawQuery {
+	return &RawQuery{c, query, args}
+}
+
+// GetAll receives a pointer to an empty slice of models and reads all the rows
+// of the query into it.
+func (raw *RawQuery) GetAll(models interface{}) error {
+	t := reflect.TypeOf(models)
+	if t.Kind() != reflect.Ptr || t.Elem().Kind() != reflect.Slice {
+		return fmt.Errorf("database: pass a pointer to a slice to GetAll")
+	}
+	if modelt := reflect.TypeOf(raw.c.model); t.Elem().Elem() != modelt {
+		return fmt.Errorf("database: expected a slice of %s and got a slice of %s", modelt, t.Elem().Elem())
+	}
+
+	return raw.run(models)
+}
+
+// First reads the first row of the query into the model. If there are no rows
+// ErrNoSuchEntity is returned.
+func (raw *RawQuery) First(instance Model) error {
+	if modelt, instancet := reflect.TypeOf(raw.c.model), reflect.TypeOf(instance); modelt != instancet {
+		return fmt.Errorf("database: expected instance of %s and got a instance of %s", modelt, instancet)
+	}
+
+	return raw.run(instance)
+}
+
+func (raw *RawQuery) run(dest interface{}) error {
+	query, args, err := bindParams(raw.c.dialect, raw.query, raw.args)
+	if err != nil {
+		return err
+	}
+	if raw.c.debug {
+		log.Println("database [Raw]:", query)
+	}
+
+	rows, err := raw.c.query(query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	return scanRaw(rows, dest)
+}
+
+// scanRaw reads the rows of a raw query in a pointer to a slice of structs or to
+// a single struct.
+func scanRaw(rows *sql.Rows, dest interface{}) error {
+	v := reflect.ValueOf(dest)
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		return fmt.Errorf("database: pass a pointer to a slice or a struct to read the results")
+	}
+	v = v.Elem()
+
+	var single bool
+	structt := v.Type()
+	switch structt.Kind() {
+	case reflect.Struct:
+		single = true
+	case reflect.Slice:
+		structt = structt.Elem()
+		if structt.Kind() == reflect.Ptr {
+			structt = structt.Elem()
+		}
+	}
+	if structt.Kind() != reflect.Struct {
+		return fmt.Errorf("database: pass a pointer to a slice or a struct to read the results, got %s", v.Type())
+	}
+
+	meta, err := modelMetadataOf(reflect.PtrTo(structt))
+	if err != nil {
+		return err
+	}
+	columns, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+	fields := make([]*fieldMetadata, len(columns))
+	for i, column := range columns {
+		for _, field := range meta.fields {
+			if unquoteColumn(field.name) == column {
+				fields[i] = field
+				break
+			}
+		}
+	}
+
+	results := reflect.MakeSlice(reflect.SliceOf(reflect.PtrTo(structt)), 0, 0)
+	for rows.Next() {
+		result := reflect.New(structt)
+
+		pointers := make([]interface{}, len(columns))
+		for i, field := range fields {
+			if field == nil {
+				pointers[i] = new(interface{})
+				continue
+			}
+			pointers[i] = result.Elem().FieldByIndex(field.index).Addr().Interface()
+		}
+		if err := rows.Scan(pointers...); err != nil {
+			return err
+		}
+
+		if model, ok := result.Interface().(Model); ok {
+			if err := model.Tracking().AfterGet(meta.props(result.Elem())); err != nil {
+				return err
+			}
+		}
+
+		results = reflect.Append(results, result)
+		if single {
+			break
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	if single {
+		if results.Len() == 0 {
+			return ErrNoSuchEntity
+		}
+		v.Set(results.Index(0).Elem())
+		return nil
+	}
+
+	if v.Type().Elem().Kind() == reflect.Ptr {
+		v.Set(results)
+		return nil
+	}
+	values := reflect.MakeSlice(v.Type(), results.Len(), results.Len())
+	for i := 0; i < results.Len(); i++ {
+		values.Index(i).Set(results.Index(i).Elem())
+	}
+	v.Set(values)
+
+	return nil
+}
@@ -0,0 +1,59 @@
+package database
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type testingReport struct {
+	Parent int64 `db:"parent"`
+	Total  int64 `db:"total"`
+	Unused string
+}
+
+func TestQuery(t *testing.T) {
+	initDatabase(t)
+	defer closeDatabase()
+	insertJoinFixtures(t)
+
+	var reports []*testingReport
+	require.Nil(t, testDB.Query(&reports, `SELECT parent, COUNT(*) AS total, MAX(foo) AS extra FROM testing_relchild GROUP BY parent ORDER BY parent`))
+
+	require.Len(t, reports, 2)
+	require.EqualValues(t, 1, reports[0].Parent)
+	require.EqualValues(t, 1, reports[0].Total)
+	require.EqualValues(t, 2, reports[1].Parent)
+	require.EqualValues(t, 2, reports[1].Total)
+
+	var values []testingReport
+	require.Nil(t, testDB.Query(&values, `SELECT parent FROM testing_relchild WHERE foo = ?`, "c"))
+	require.Equal(t, []testingReport{{Parent: 2}}, values)
+
+	var report testingReport
+	require.Equal(t, ErrNoSuchEntity, testDB.Query(&report, `SELECT parent FROM testing_relchild WHERE foo = ?`, "d"))
+}
+
+func TestRaw(t *testing.T) {
+	initDatabase(t)
+	defer closeDatabase()
+
+	require.Nil(t, testings.Put(&testingModel{Code: "foo", Name: "foo name"}))
+	require.Nil(t, testings.Put(&testingModel{Code: "bar", Name: "bar name"}))
+
+	var models []*testingModel
+	require.Nil(t, testings.Raw(`SELECT code, name, revision FROM testing WHERE LENGTH(name) > ? ORDER BY code`, 3).GetAll(&models))
+	require.Len(t, models, 2)
+	require.Equal(t, "bar", models[0].Code)
+	require.True(t, models[0].IsInserted())
+
+	models[0].Name = "updated"
+	require.Nil(t, testings.Put(models[0]))
+
+	model := new(testingModel)
+	require.Nil(t, testings.Raw(`SELECT * FROM testing WHERE code = ?`, "bar").First(model))
+	require.Equal(t, "updated", model.Name)
+	require.EqualValues(t, 1, model.Tracking().StoredRevision())
+
+	require.Equal(t, ErrNoSuchEntity, testings.Raw(`SELECT * FROM testing WHERE code = ?`, "baz").First(new(testingModel)))
+}
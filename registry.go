@@ -0,0 +1,28 @@
+package database
+
+import (
+	"sync"
+)
+
+var registry struct {
+	sync.Mutex
+	models []Model
+}
+
+// RegisterModels adds models to a global registry. Tools that need the full list
+// of models of the application, like the schema verification of dbctl, read it
+// from there. Call it in an init function of the package that declares the models.
+func RegisterModels(models ...Model) {
+	registry.Lock()
+	defer registry.Unlock()
+
+	registry.models = append(registry.models, models...)
+}
+
+// RegisteredModels returns the list of models added with RegisterModels.
+func RegisteredModels() []Model {
+	registry.Lock()
+	defer registry.Unlock()
+
+	return append([]Model(nil), registry.models...)
+}
@@ -0,0 +1,16 @@
+package database
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegisterModels(t *testing.T) {
+	RegisterModels(new(testingModel), new(testingAutoModel))
+
+	models := RegisteredModels()
+	require.Len(t, models, 2)
+	require.IsType(t, new(testingModel), models[0])
+	require.IsType(t, new(testingAutoModel), models[1])
+}
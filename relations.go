@@ -0,0 +1,275 @@
+package database
+
+import (
+	"fmt"
+	"reflect"
+)
+
+var modelType = reflect.TypeOf((*Model)(nil)).Elem()
+
+type relationKind int
+
+const (
+	// belongsTo relations store the primary key of the related model in a
+	// field of the model.
+	belongsTo relationKind = iota
+
+	// hasMany relations store the primary key of the model in a field of
+	// each one of the related models.
+	hasMany
+)
+
+// relationMetadata contains the information of a struct field that stores
+// other models related to this one.
+type relationMetadata struct {
+	kind relationKind
+
+	// Struct field name with the foreign key. In belongsTo relations it is a
+	// field of the model, in hasMany relations it is a field of the related model.
+	key string
+
+	// Index sequence to reach the field inside the model struct.
+	index []int
+
+	// Pointer type of the related model.
+	model reflect.Type
+}
+
+func (meta *modelMetadata) addRelation(ft reflect.StructField, index []int, fieldPrefix string, relation *relationMetadata) error {
+	t := ft.Type
+	if relation.kind == hasMany {
+		if t.Kind() != reflect.Slice {
+			return fmt.Errorf("database: hasmany relation %s should be a slice of models", ft.Name)
+		}
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Ptr || !t.Implements(modelType) {
+		return fmt.Errorf("database: relation %s should point to a model", ft.Name)
+	}
+
+	relation.index = index
+	relation.model = t
+	meta.relations[fieldPrefix+ft.Name] = relation
+
+	return nil
+}
+
+// fieldByName returns the metadata of the column stored in a struct field.
+func (meta *modelMetadata) fieldByName(name string) *fieldMetadata {
+	for _, field := range meta.fields {
+		if field.field == name {
+			return field
+		}
+	}
+
+	return nil
+}
+
+// primaryKey returns the single primary key of the model or an error if it has
+// none or more than one.
+func (meta *modelMetadata) primaryKey() (*fieldMetadata, error) {
+	var pk *fieldMetadata
+	for _, field := range meta.fields {
+		if field.pk {
+			if pk != nil {
+				return nil, fmt.Errorf("database: relations need models with a single primary key")
+			}
+			pk = field
+		}
+	}
+	if pk == nil {
+		return nil, fmt.Errorf("database: relations need models with a primary key")
+	}
+
+	return pk, nil
+}
+
+// Preload loads the models of a relation after the main query of GetAll, GetMulti,
+// Get, First and each batch of ForEachBatch, issuing a single additional query for
+// the whole relation instead of one per model. Iterator, All, Stream and
+// GetAllComposite return an error if the collection has relations to preload.
+// Relations are declared in the struct tags of the model:
+//
+//	type Room struct {
+//	  database.ModelTracking
+//
+//	  ID      int64  `db:"id,pk"`
+//	  HotelID int64  `db:"hotel_id"`
+//	  Hotel   *Hotel `db:"-,belongsto=HotelID"`
+//	}
+//
+//	type Hotel struct {
+//	  database.ModelTracking
+//
+//	  ID    int64   `db:"id,pk"`
+//	  Rooms []*Room `db:"-,hasmany=HotelID"`
+//	}
+//
+// In belongsto relations the key is the field of the model with the primary key
+// of the related one. In hasmany relations the key is the field of the related
+// models with the primary key of this one. Preload will panic if the relation
+// does not exist in the model.
+func (c *Collection) Preload(relation string) *Collection {
+	if _, ok := c.meta.relations[relation]; !ok {
+		panic(fmt.Errorf("database: unknown relation %s in model %T", relation, c.model))
+	}
+
+	c.preloads = append(c.preloads, relation)
+	return c
+}
+
+// preloadOne loads the relations of a single model.
+func (c *Collection) preloadOne(instance Model) error {
+	if len(c.preloads) == 0 {
+		return nil
+	}
+
+	models := reflect.MakeSlice(reflect.SliceOf(reflect.TypeOf(instance)), 0, 1)
+	return c.preload(reflect.Append(models, reflect.ValueOf(instance)))
+}
+
+// preload loads the relations of a slice of models.
+func (c *Collection) preload(models reflect.Value) error {
+	if models.Len() == 0 {
+		return nil
+	}
+
+	for _, name := range c.preloads {
+		relation := c.meta.relations[name]
+		related := newCollection(&Database{sess: c.sess, debug: c.debug, dialect: c.dialect}, reflect.New(relation.model.Elem()).Interface().(Model))
+
+		var err error
+		switch relation.kind {
+		case belongsTo:
+			err = c.preloadBelongsTo(models, relation, related)
+		case hasMany:
+			err = c.preloadHasMany(models, relation, related)
+		}
+		if err != nil {
+			return fmt.Errorf("database: cannot preload %s: %s", name, err)
+		}
+	}
+
+	return nil
+}
+
+func (c *Collection) preloadBelongsTo(models reflect.Value, relation *relationMetadata, related *Collection) error {
+	fk := c.meta.fieldByName(relation.key)
+	if fk == nil {
+		return fmt.Errorf("unknown key field %s", relation.key)
+	}
+
+	keys := reflect.MakeSlice(reflect.SliceOf(fk.typ), 0, models.Len())
+	seen := map[interface{}]bool{}
+	for i := 0; i < models.Len(); i++ {
+		key := models.Index(i).Elem().FieldByIndex(fk.index).Interface()
+		if isZero(key) || seen[key] {
+			continue
+		}
+		seen[key] = true
+		keys = reflect.Append(keys, reflect.ValueOf(key))
+	}
+	if keys.Len() == 0 {
+		return nil
+	}
+
+	dest := reflect.New(reflect.SliceOf(relation.model))
+	if err := related.GetMulti(keys.Interface(), dest.Interface()); err != nil {
+		if _, ok := err.(MultiError); !ok {
+			return err
+		}
+	}
+
+	// GetMulti returns the models in the same order as the keys and nil when
+	// they were not found.
+	byKey := map[interface{}]reflect.Value{}
+	for i := 0; i < keys.Len(); i++ {
+		byKey[keys.Index(i).Interface()] = dest.Elem().Index(i)
+	}
+	for i := 0; i < models.Len(); i++ {
+		model := models.Index(i).Elem()
+		if found, ok := byKey[model.FieldByIndex(fk.index).Interface()]; ok {
+			model.FieldByIndex(relation.index).Set(found)
+		}
+	}
+
+	return nil
+}
+
+func (c *Collection) preloadHasMany(models reflect.Value, relation *relationMetadata, related *Collection) error {
+	pk, err := c.meta.primaryKey()
+	if err != nil {
+		return err
+	}
+	fk := related.meta.fieldByName(relation.key)
+	if fk == nil {
+		return fmt.Errorf("unknown key field %s", relation.key)
+	}
+
+	var keys []interface{}
+	seen := map[interface{}]bool{}
+	for i := 0; i < models.Len(); i++ {
+		key := models.Index(i).Elem().FieldByIndex(pk.index).Interface()
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		keys = append(keys, key)
+	}
+
+	related = related.Filter(fmt.Sprintf("%s IN", fk.name), keys)
+	for _, field := range related.meta.fields {
+		if field.pk {
+			related.orders = append(related.orders, &sqlSorter{sql: fmt.Sprintf("%s ASC", field.name)})
+		}
+	}
+
+	dest := reflect.New(reflect.SliceOf(relation.model))
+	if err := related.GetAll(dest.Interface()); err != nil {
+		return err
+	}
+
+	sliceType := reflect.SliceOf(relation.model)
+	groups := map[interface{}]reflect.Value{}
+	for i := 0; i < dest.Elem().Len(); i++ {
+		child := dest.Elem().Index(i)
+		key, ok := relationKey(child.Elem().FieldByIndex(fk.index), pk.typ)
+		if !ok {
+			continue
+		}
+
+		group, ok := groups[key]
+		if !ok {
+			group = reflect.MakeSlice(sliceType, 0, 1)
+		}
+		groups[key] = reflect.Append(group, child)
+	}
+	for i := 0; i < models.Len(); i++ {
+		model := models.Index(i).Elem()
+
+		group, ok := groups[model.FieldByIndex(pk.index).Interface()]
+		if !ok {
+			group = reflect.MakeSlice(sliceType, 0, 0)
+		}
+		model.FieldByIndex(relation.index).Set(group)
+	}
+
+	return nil
+}
+
+// relationKey converts the value of a foreign key to the type of the primary key
+// it references, so they can be compared even if the fields have different integer
+// types or the foreign key is a pointer. It returns false for nil pointers.
+func relationKey(v reflect.Value, t reflect.Type) (interface{}, bool) {
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil, false
+		}
+		v = v.Elem()
+	}
+	if v.Type() != t && v.Type().ConvertibleTo(t) && (v.Kind() == reflect.String) == (t.Kind() == reflect.String) {
+		v = v.Convert(t)
+	}
+
+	return v.Interface(), true
+}
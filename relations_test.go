@@ -0,0 +1,199 @@
+package database
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type testingRelParentMixed struct {
+	ModelTracking
+
+	ID int64 `db:"id,pk"`
+
+	Children []*testingRelChildPtr `db:"-,hasmany=Parent"`
+}
+
+func (model *testingRelParentMixed) TableName() string {
+	return "testing_relparent"
+}
+
+type testingRelChildPtr struct {
+	ModelTracking
+
+	ID int64 `db:"id,pk"`
+
+	Parent *int32 `db:"parent"`
+	Foo    string `db:"foo"`
+}
+
+func (model *testingRelChildPtr) TableName() string {
+	return "testing_relchild"
+}
+
+func TestPreloadUnknownRelationPanics(t *testing.T) {
+	defer func() {
+		err, ok := recover().(error)
+		require.True(t, ok)
+		require.EqualError(t, err, "database: unknown relation Foo in model *database.testingRelParent")
+	}()
+
+	newCollection(new(Database), new(testingRelParent)).Preload("Foo")
+}
+
+func TestPreloadIterator(t *testing.T) {
+	c := newCollection(new(Database), new(testingRelParent)).Preload("Children")
+
+	_, err := c.Iterator()
+	require.EqualError(t, err, "database: iterators cannot preload relations, use GetAll or ForEachBatch instead")
+
+	for _, err := range c.All() {
+		require.EqualError(t, err, "database: iterators cannot preload relations, use GetAll or ForEachBatch instead")
+	}
+}
+
+func TestRelationNotIgnored(t *testing.T) {
+	type model struct {
+		testingModel
+
+		Parent *testingRelParent `db:"parent,belongsto=ID"`
+	}
+
+	_, err := extractModelProps(new(model))
+	require.EqualError(t, err, `database: relation Parent should be tagged with the column name "-"`)
+}
+
+func TestRelationNotModel(t *testing.T) {
+	type model struct {
+		testingModel
+
+		Children []string `db:"-,hasmany=Parent"`
+	}
+
+	_, err := extractModelProps(new(model))
+	require.EqualError(t, err, "database: relation Children should point to a model")
+}
+
+func TestPreloadBelongsTo(t *testing.T) {
+	initDatabase(t)
+	defer closeDatabase()
+
+	require.Nil(t, testingsRelParent.Put(new(testingRelParent)))
+	require.Nil(t, testingsRelParent.Put(new(testingRelParent)))
+
+	require.Nil(t, testingsRelChild.Put(&testingRelChild{Parent: 1, Foo: "foo"}))
+	require.Nil(t, testingsRelChild.Put(&testingRelChild{Parent: 2, Foo: "bar"}))
+	require.Nil(t, testingsRelChild.Put(&testingRelChild{Parent: 1, Foo: "baz"}))
+	require.Nil(t, testingsRelChild.Put(&testingRelChild{Parent: 3, Foo: "qux"}))
+
+	var models []*testingRelChild
+	require.Nil(t, testingsRelChild.Preload("ParentModel").GetAll(&models))
+
+	require.Len(t, models, 4)
+	require.EqualValues(t, 1, models[0].ParentModel.ID)
+	require.EqualValues(t, 2, models[1].ParentModel.ID)
+	require.EqualValues(t, 1, models[2].ParentModel.ID)
+	require.Nil(t, models[3].ParentModel)
+
+	require.True(t, models[0].ParentModel.IsInserted())
+}
+
+func TestPreloadHasMany(t *testing.T) {
+	initDatabase(t)
+	defer closeDatabase()
+
+	require.Nil(t, testingsRelParent.Put(new(testingRelParent)))
+	require.Nil(t, testingsRelParent.Put(new(testingRelParent)))
+
+	require.Nil(t, testingsRelChild.Put(&testingRelChild{Parent: 1, Foo: "foo"}))
+	require.Nil(t, testingsRelChild.Put(&testingRelChild{Parent: 1, Foo: "bar"}))
+
+	var models []*testingRelParent
+	require.Nil(t, testingsRelParent.Preload("Children").GetAll(&models))
+
+	require.Len(t, models, 2)
+	require.Len(t, models[0].Children, 2)
+	require.Equal(t, "foo", models[0].Children[0].Foo)
+	require.Equal(t, "bar", models[0].Children[1].Foo)
+	require.NotNil(t, models[1].Children)
+	require.Len(t, models[1].Children, 0)
+}
+
+func TestPreloadGet(t *testing.T) {
+	initDatabase(t)
+	defer closeDatabase()
+
+	require.Nil(t, testingsRelParent.Put(new(testingRelParent)))
+	require.Nil(t, testingsRelChild.Put(&testingRelChild{Parent: 1, Foo: "foo"}))
+
+	m := &testingRelParent{ID: 1}
+	require.Nil(t, testingsRelParent.Clone().Preload("Children").Get(m))
+
+	require.Len(t, m.Children, 1)
+	require.Equal(t, "foo", m.Children[0].Foo)
+}
+
+func TestPreloadForEachBatch(t *testing.T) {
+	initDatabase(t)
+	defer closeDatabase()
+
+	require.Nil(t, testingsRelParent.Put(new(testingRelParent)))
+	require.Nil(t, testingsRelParent.Put(new(testingRelParent)))
+	require.Nil(t, testingsRelChild.Put(&testingRelChild{Parent: 2, Foo: "foo"}))
+
+	var models []*testingRelParent
+	err := testingsRelParent.Clone().Preload("Children").ForEachBatch(1, func(batch []Model) error {
+		for _, model := range batch {
+			models = append(models, model.(*testingRelParent))
+		}
+		return nil
+	})
+	require.Nil(t, err)
+
+	require.Len(t, models, 2)
+	require.Len(t, models[0].Children, 0)
+	require.Len(t, models[1].Children, 1)
+	require.Equal(t, "foo", models[1].Children[0].Foo)
+}
+
+func TestPreloadHasManyMismatchedKeyTypes(t *testing.T) {
+	initDatabase(t)
+	defer closeDatabase()
+
+	require.Nil(t, testingsRelParent.Put(new(testingRelParent)))
+	require.Nil(t, testingsRelParent.Put(new(testingRelParent)))
+
+	parent := int32(2)
+	children := testDB.Collection(new(testingRelChildPtr))
+	require.Nil(t, children.Put(&testingRelChildPtr{Parent: &parent, Foo: "foo"}))
+	require.Nil(t, children.Put(&testingRelChildPtr{Foo: "bar"}))
+
+	var models []*testingRelParentMixed
+	require.Nil(t, testDB.Collection(new(testingRelParentMixed)).Preload("Children").GetAll(&models))
+
+	require.Len(t, models, 2)
+	require.Len(t, models[0].Children, 0)
+	require.Len(t, models[1].Children, 1)
+	require.Equal(t, "foo", models[1].Children[0].Foo)
+}
+
+func TestRelationKey(t *testing.T) {
+	int64Type := reflect.TypeOf(int64(0))
+
+	key, ok := relationKey(reflect.ValueOf(int32(3)), int64Type)
+	require.True(t, ok)
+	require.Equal(t, int64(3), key)
+
+	n := int64(4)
+	key, ok = relationKey(reflect.ValueOf(&n), int64Type)
+	require.True(t, ok)
+	require.Equal(t, int64(4), key)
+
+	_, ok = relationKey(reflect.ValueOf((*int64)(nil)), int64Type)
+	require.False(t, ok)
+
+	key, ok = relationKey(reflect.ValueOf("5"), int64Type)
+	require.True(t, ok)
+	require.Equal(t, "5", key)
+}
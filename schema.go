@@ -0,0 +1,330 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	nullStringType  = reflect.TypeOf(sql.NullString{})
+	nullInt64Type   = reflect.TypeOf(sql.NullInt64{})
+	nullFloat64Type = reflect.TypeOf(sql.NullFloat64{})
+	nullBoolType    = reflect.TypeOf(sql.NullBool{})
+	nullTimeType    = reflect.TypeOf(sql.NullTime{})
+	bytesType       = reflect.TypeOf([]byte{})
+
+	decimalRe = regexp.MustCompile(`^-?[0-9]+(\.[0-9]+)?$`)
+)
+
+// defaultStringSize is the size of VARCHAR columns when the model does not
+// specify one. It is the maximum size that can be indexed with utf8mb4.
+const defaultStringSize = 191
+
+// maxStringSize is the maximum size of a VARCHAR column with utf8mb4. Larger
+// strings will be stored in TEXT columns.
+const maxStringSize = 16383
+
+// columnSchema stores the tag options that only affect the generated schema
+// of a column.
+type columnSchema struct {
+	// Size of the string columns.
+	size int64
+
+	// Index the column alone or in a group of columns with the same name.
+	index     bool
+	indexName string
+
+	// Unique index of the column alone or in a group of columns with the same name.
+	unique     bool
+	uniqueName string
+
+	// Default value of the column.
+	hasDefault   bool
+	defaultValue string
+
+	// Charset of the string columns.
+	charset string
+}
+
+// CreateTableSQL returns the CREATE TABLE statement that stores the model of
+// the collection in MySQL; other dialects return an error. Columns are typed
+// after the Go type of each field and the schema can be customized with
+// additional struct tag options:
+//
+//	size=N        Size of a string column. By default 191.
+//	index         Adds an index to the column.
+//	index=name    Adds the column to a named index shared with other columns.
+//	unique        Adds a unique index to the column.
+//	unique=name   Adds the column to a named unique index shared with other columns.
+//	default=val   Default value of the column. Strings are quoted; other columns
+//	              accept numbers, TRUE or FALSE, CURRENT_TIMESTAMP for times and
+//	              NULL for nullable columns.
+//	charset=name  Character set of a string column.
+func (c *Collection) CreateTableSQL() (string, error) {
+	if c.dialect != MySQL {
+		return "", fmt.Errorf("database: schema generation is only supported in MySQL")
+	}
+
+	return c.meta.createTableSQL(c.model.TableName())
+}
+
+// CreateTable creates the table of the model in the database. See Collection.CreateTableSQL
+// for the details about the generated schema.
+func (db *Database) CreateTable(model Model) error {
+	statement, err := db.Collection(model).CreateTableSQL()
+	if err != nil {
+		return err
+	}
+
+	if db.debug {
+		log.Println("database [CreateTable]:", statement)
+	}
+
+	return db.Exec(statement)
+}
+
+type tableIndex struct {
+	name   string
+	unique bool
+	cols   []string
+}
+
+func (meta *modelMetadata) createTableSQL(table string) (string, error) {
+	var pks []*fieldMetadata
+	for _, field := range meta.fields {
+		if field.pk {
+			pks = append(pks, field)
+		}
+	}
+
+	var lines, pkCols []string
+	var indexes []*tableIndex
+	named := map[string]*tableIndex{}
+	for _, field := range meta.fields {
+		def, err := field.columnSQL(len(pks) == 1)
+		if err != nil {
+			return "", err
+		}
+		lines = append(lines, def)
+
+		if field.pk {
+			pkCols = append(pkCols, field.name)
+		}
+
+		column := strings.Trim(field.name, "`")
+		if field.schema.index {
+			name := field.schema.indexName
+			if name == "" {
+				name = "idx_" + column
+			}
+			indexes, err = appendIndex(indexes, named, name, false, field.name)
+			if err != nil {
+				return "", err
+			}
+		}
+		if field.schema.unique {
+			name := field.schema.uniqueName
+			if name == "" {
+				name = "uniq_" + column
+			}
+			indexes, err = appendIndex(indexes, named, name, true, field.name)
+			if err != nil {
+				return "", err
+			}
+		}
+	}
+
+	if len(pkCols) > 0 {
+		lines = append(lines, fmt.Sprintf("PRIMARY KEY(%s)", strings.Join(pkCols, ", ")))
+	}
+	for _, index := range indexes {
+		if index.unique {
+			lines = append(lines, fmt.Sprintf("UNIQUE KEY `%s` (%s)", index.name, strings.Join(index.cols, ", ")))
+		} else {
+			lines = append(lines, fmt.Sprintf("KEY `%s` (%s)", index.name, strings.Join(index.cols, ", ")))
+		}
+	}
+
+	return fmt.Sprintf("CREATE TABLE %s (\n  %s\n) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4", quoteColumn(table), strings.Join(lines, ",\n  ")), nil
+}
+
+func appendIndex(indexes []*tableIndex, named map[string]*tableIndex, name string, unique bool, col string) ([]*tableIndex, error) {
+	if index, ok := named[name]; ok {
+		if index.unique != unique {
+			return nil, fmt.Errorf("database: index %s is used both as a normal and a unique index", name)
+		}
+		index.cols = append(index.cols, col)
+		return indexes, nil
+	}
+
+	index := &tableIndex{
+		name:   name,
+		unique: unique,
+		cols:   []string{col},
+	}
+	named[name] = index
+	return append(indexes, index), nil
+}
+
+// columnSQL returns the definition of the column inside the CREATE TABLE statement.
+func (field *fieldMetadata) columnSQL(singlePK bool) (string, error) {
+	sqlType, t, nullable, err := field.sqlType()
+	if err != nil {
+		return "", err
+	}
+	if t.Kind() == reflect.String && field.schema.charset != "" {
+		sqlType = fmt.Sprintf("%s CHARACTER SET %s", sqlType, field.schema.charset)
+	}
+
+	def := fmt.Sprintf("%s %s", field.name, sqlType)
+	if !nullable || field.pk {
+		def += " NOT NULL"
+	}
+	if field.pk && singlePK && field.typ.Kind() == reflect.Int64 {
+		def += " AUTO_INCREMENT"
+	}
+
+	switch {
+	case field.schema.hasDefault:
+		value, err := field.defaultSQL(t, nullable)
+		if err != nil {
+			return "", err
+		}
+		def += " DEFAULT " + value
+
+	// Columns omitted when empty need a default value to insert the rows.
+	case field.omitEmpty && !field.pk && !nullable:
+		switch t.Kind() {
+		case reflect.String:
+			def += " DEFAULT ''"
+		case reflect.Int32, reflect.Int64:
+			def += " DEFAULT 0"
+		}
+	}
+
+	return def, nil
+}
+
+// defaultSQL returns the literal of the default value of the column. Values of
+// columns that are not strings are checked because they are not quoted.
+func (field *fieldMetadata) defaultSQL(t reflect.Type, nullable bool) (string, error) {
+	value := field.schema.defaultValue
+	if nullable && strings.ToUpper(value) == "NULL" {
+		return "NULL", nil
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		// Backslashes mean different things depending on the NO_BACKSLASH_ESCAPES mode.
+		if !strings.Contains(value, `\`) {
+			return fmt.Sprintf("'%s'", strings.Replace(value, "'", "''", -1)), nil
+		}
+
+	case reflect.Bool:
+		switch strings.ToUpper(value) {
+		case "TRUE", "1":
+			return "TRUE", nil
+		case "FALSE", "0":
+			return "FALSE", nil
+		}
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if _, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return value, nil
+		}
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if _, err := strconv.ParseUint(value, 10, 64); err == nil {
+			return value, nil
+		}
+
+	case reflect.Float32, reflect.Float64:
+		if decimalRe.MatchString(value) {
+			return value, nil
+		}
+	}
+	if t == timeType && strings.ToUpper(value) == "CURRENT_TIMESTAMP" {
+		return "CURRENT_TIMESTAMP", nil
+	}
+
+	return "", fmt.Errorf("database: invalid default value for field %s: %s", field.field, value)
+}
+
+// sqlType returns the MySQL type of the column, the Go type it will be
+// scanned to once nullable wrappers are removed and if the column accepts NULL.
+func (field *fieldMetadata) sqlType() (string, reflect.Type, bool, error) {
+	t := field.typ
+	nullable := false
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+		nullable = true
+	}
+
+	switch t {
+	case nullStringType:
+		t = reflect.TypeOf("")
+		nullable = true
+	case nullInt64Type:
+		t = reflect.TypeOf(int64(0))
+		nullable = true
+	case nullFloat64Type:
+		t = reflect.TypeOf(float64(0))
+		nullable = true
+	case nullBoolType:
+		t = reflect.TypeOf(false)
+		nullable = true
+	case nullTimeType:
+		t = timeType
+		nullable = true
+	}
+
+	switch t {
+	case timeType:
+		return "DATETIME", t, nullable, nil
+
+	case bytesType:
+		return "BLOB", t, nullable, nil
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		size := field.schema.size
+		if size == 0 {
+			size = defaultStringSize
+		}
+		if size > maxStringSize {
+			return "TEXT", t, nullable, nil
+		}
+		return fmt.Sprintf("VARCHAR(%d)", size), t, nullable, nil
+
+	case reflect.Bool:
+		return "BOOLEAN", t, nullable, nil
+	case reflect.Int8:
+		return "TINYINT", t, nullable, nil
+	case reflect.Int16:
+		return "SMALLINT", t, nullable, nil
+	case reflect.Int32:
+		return "INT", t, nullable, nil
+	case reflect.Int, reflect.Int64:
+		return "BIGINT", t, nullable, nil
+	case reflect.Uint8:
+		return "TINYINT UNSIGNED", t, nullable, nil
+	case reflect.Uint16:
+		return "SMALLINT UNSIGNED", t, nullable, nil
+	case reflect.Uint32:
+		return "INT UNSIGNED", t, nullable, nil
+	case reflect.Uint, reflect.Uint64:
+		return "BIGINT UNSIGNED", t, nullable, nil
+	case reflect.Float32:
+		return "FLOAT", t, nullable, nil
+	case reflect.Float64:
+		return "DOUBLE", t, nullable, nil
+	}
+
+	return "", nil, false, fmt.Errorf("database: cannot generate the schema of field %s with type %s", field.field, field.typ)
+}
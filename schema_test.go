@@ -0,0 +1,251 @@
+package database
+
+import (
+	"database/sql"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type testingSchemaModel struct {
+	ModelTracking
+
+	ID       int64          `db:"id,pk"`
+	Name     string         `db:"name,size=50,index"`
+	Slug     string         `db:"slug,unique,charset=ascii"`
+	Status   string         `db:"status,default=draft"`
+	Content  string         `db:"content,size=20000"`
+	Active   bool           `db:"active"`
+	Score    float64        `db:"score"`
+	Count    int32          `db:"count,omitempty"`
+	Created  time.Time      `db:"created,index=idx_created_active"`
+	Deleted  *time.Time     `db:"deleted"`
+	Note     sql.NullString `db:"note"`
+	Billing  testingAddress `db:",inline,prefix=billing_"`
+	Ignored  string         `db:"-"`
+	Internal []string       `db:"-"`
+}
+
+func (model *testingSchemaModel) TableName() string {
+	return "testing_schema"
+}
+
+func TestCreateTableSQL(t *testing.T) {
+	c := newCollection(new(Database), new(testingSchemaModel))
+
+	statement, err := c.CreateTableSQL()
+	require.Nil(t, err)
+
+	require.Equal(t, "CREATE TABLE `testing_schema` (\n"+
+		"  `revision` BIGINT NOT NULL,\n"+
+		"  `id` BIGINT NOT NULL AUTO_INCREMENT,\n"+
+		"  `name` VARCHAR(50) NOT NULL,\n"+
+		"  `slug` VARCHAR(191) CHARACTER SET ascii NOT NULL,\n"+
+		"  `status` VARCHAR(191) NOT NULL DEFAULT 'draft',\n"+
+		"  `content` TEXT NOT NULL,\n"+
+		"  `active` BOOLEAN NOT NULL,\n"+
+		"  `score` DOUBLE NOT NULL,\n"+
+		"  `count` INT NOT NULL DEFAULT 0,\n"+
+		"  `created` DATETIME NOT NULL,\n"+
+		"  `deleted` DATETIME,\n"+
+		"  `note` VARCHAR(191),\n"+
+		"  `billing_street` VARCHAR(191) NOT NULL,\n"+
+		"  `billing_city` VARCHAR(191) NOT NULL,\n"+
+		"  PRIMARY KEY(`id`),\n"+
+		"  KEY `idx_name` (`name`),\n"+
+		"  UNIQUE KEY `uniq_slug` (`slug`),\n"+
+		"  KEY `idx_created_active` (`created`)\n"+
+		") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4", statement)
+}
+
+func TestCreateTableSQLCompositePrimaryKey(t *testing.T) {
+	type model struct {
+		testingModel
+
+		Parent int64  `db:"parent,pk"`
+		Code   string `db:"code,pk"`
+	}
+	c := newCollection(new(Database), new(model))
+
+	statement, err := c.CreateTableSQL()
+	require.Nil(t, err)
+
+	require.Equal(t, "CREATE TABLE `testing` (\n"+
+		"  `parent` BIGINT NOT NULL,\n"+
+		"  `code` VARCHAR(191) NOT NULL,\n"+
+		"  PRIMARY KEY(`parent`, `code`)\n"+
+		") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4", statement)
+}
+
+func TestCreateTableSQLUnsupportedType(t *testing.T) {
+	type model struct {
+		testingModel
+
+		Tags []string `db:"tags"`
+	}
+	c := newCollection(new(Database), new(model))
+
+	_, err := c.CreateTableSQL()
+	require.EqualError(t, err, "database: cannot generate the schema of field Tags with type []string")
+}
+
+func TestCreateTableSQLDefaults(t *testing.T) {
+	type model struct {
+		testingModel
+
+		Status  string     `db:"status,default=it's"`
+		Count   int32      `db:"count,default=-3"`
+		Score   float64    `db:"score,default=1.5"`
+		Active  bool       `db:"active,default=true"`
+		Created time.Time  `db:"created,default=current_timestamp"`
+		Deleted *time.Time `db:"deleted,default=NULL"`
+	}
+	c := newCollection(new(Database), new(model))
+
+	statement, err := c.CreateTableSQL()
+	require.Nil(t, err)
+
+	require.Equal(t, "CREATE TABLE `testing` (\n"+
+		"  `status` VARCHAR(191) NOT NULL DEFAULT 'it''s',\n"+
+		"  `count` INT NOT NULL DEFAULT -3,\n"+
+		"  `score` DOUBLE NOT NULL DEFAULT 1.5,\n"+
+		"  `active` BOOLEAN NOT NULL DEFAULT TRUE,\n"+
+		"  `created` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,\n"+
+		"  `deleted` DATETIME DEFAULT NULL\n"+
+		") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4", statement)
+}
+
+func TestCreateTableSQLInvalidDefault(t *testing.T) {
+	type model struct {
+		testingModel
+
+		Count int32 `db:"count,default=0; DROP TABLE foo"`
+	}
+	c := newCollection(new(Database), new(model))
+
+	_, err := c.CreateTableSQL()
+	require.EqualError(t, err, "database: invalid default value for field Count: 0; DROP TABLE foo")
+}
+
+func TestCreateTableSQLStringDefaultBackslash(t *testing.T) {
+	type model struct {
+		testingModel
+
+		Name string `db:"name,default=foo\\"`
+	}
+	c := newCollection(new(Database), new(model))
+
+	_, err := c.CreateTableSQL()
+	require.EqualError(t, err, `database: invalid default value for field Name: foo\`)
+}
+
+func TestCreateTableSQLIndexConflict(t *testing.T) {
+	type model struct {
+		testingModel
+
+		Name string `db:"name,index=idx_foo"`
+		Slug string `db:"slug,unique=idx_foo"`
+	}
+	c := newCollection(new(Database), new(model))
+
+	_, err := c.CreateTableSQL()
+	require.EqualError(t, err, "database: index idx_foo is used both as a normal and a unique index")
+}
+
+func TestCreateTable(t *testing.T) {
+	skipSQLite(t)
+	initDatabase(t)
+	defer closeDatabase()
+
+	require.Nil(t, testDB.Exec(`DROP TABLE IF EXISTS testing_schema`))
+	require.Nil(t, testDB.CreateTable(new(testingSchemaModel)))
+
+	schemas := testDB.Collection(new(testingSchemaModel))
+
+	m := &testingSchemaModel{
+		Name:    "foo",
+		Slug:    "foo",
+		Created: time.Now().Truncate(time.Second),
+	}
+	require.Nil(t, schemas.Put(m))
+	require.EqualValues(t, 1, m.ID)
+
+	other := &testingSchemaModel{
+		ID: 1,
+	}
+	require.Nil(t, schemas.Get(other))
+	require.Equal(t, "foo", other.Name)
+	require.Nil(t, other.Deleted)
+	require.False(t, other.Note.Valid)
+}
+
+func TestCompareSchema(t *testing.T) {
+	meta, err := modelMetadataOf(reflect.TypeOf(new(testingRelChild)))
+	require.Nil(t, err)
+
+	names := []string{"id", "parent", "foo", "extra"}
+	columns := map[string]*tableColumn{
+		"id":     {dataType: "int", primaryKey: false},
+		"parent": {dataType: "varchar"},
+		"foo":    {dataType: "text"},
+		"extra":  {dataType: "int"},
+	}
+	mismatches := compareSchema("testing_relchild", meta, names, columns)
+
+	require.Equal(t, []SchemaMismatch{
+		{"testing_relchild", "revision", "missing column"},
+		{"testing_relchild", "id", "missing primary key"},
+		{"testing_relchild", "parent", "type int64 cannot be stored in a varchar column"},
+		{"testing_relchild", "extra", "extra column"},
+	}, mismatches)
+}
+
+func TestCompareSchemaCaseInsensitive(t *testing.T) {
+	type model struct {
+		ModelTracking
+
+		Code        string `db:"code,pk"`
+		Name        string `db:"name"`
+		Description string
+	}
+	meta, err := modelMetadataOf(reflect.TypeOf(new(model)))
+	require.Nil(t, err)
+
+	names := []string{"Code", "NAME", "DESCRIPTION", "revision"}
+	columns := map[string]*tableColumn{
+		"Code":        {dataType: "varchar", primaryKey: true},
+		"NAME":        {dataType: "varchar"},
+		"DESCRIPTION": {dataType: "text"},
+		"revision":    {dataType: "int"},
+	}
+	require.Empty(t, compareSchema("testing", meta, names, columns))
+}
+
+func TestCompareSchemaMissingTable(t *testing.T) {
+	meta, err := modelMetadataOf(reflect.TypeOf(new(testingRelChild)))
+	require.Nil(t, err)
+
+	mismatches := compareSchema("testing_relchild", meta, nil, nil)
+	require.Equal(t, []SchemaMismatch{{Table: "testing_relchild", Problem: "missing table"}}, mismatches)
+}
+
+func TestVerifySchema(t *testing.T) {
+	skipSQLite(t)
+	initDatabase(t)
+	defer closeDatabase()
+
+	require.Nil(t, testDB.VerifySchema(new(testingModel), new(testingAutoModel), new(testingRelChild), new(testingEmbeddedModel)))
+}
+
+func TestVerifySchemaErrors(t *testing.T) {
+	skipSQLite(t)
+	initDatabase(t)
+	defer closeDatabase()
+
+	require.Nil(t, testDB.Exec(`DROP TABLE IF EXISTS testing_schema`))
+
+	err := testDB.VerifySchema(new(testingModel), new(testingSchemaModel), new(testingHooker))
+	require.EqualError(t, err, "database: schema mismatch: testing_schema: missing table")
+}
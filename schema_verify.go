@@ -0,0 +1,158 @@
+package database
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// compatibleTypes lists the MySQL data types that can be scanned to each
+// family of Go types.
+var compatibleTypes = map[reflect.Kind][]string{
+	reflect.String:  {"char", "varchar", "tinytext", "text", "mediumtext", "longtext", "enum", "set", "json"},
+	reflect.Bool:    {"tinyint", "bit"},
+	reflect.Int:     {"tinyint", "smallint", "mediumint", "int", "bigint"},
+	reflect.Float64: {"float", "double", "decimal"},
+	reflect.Struct:  {"datetime", "timestamp", "date"},
+	reflect.Slice:   {"binary", "varbinary", "tinyblob", "blob", "mediumblob", "longblob"},
+}
+
+// VerifySchema checks that the tables of the models exist in the database and
+// have the expected columns, types and primary keys. It is useful to run it when
+// the application starts or in a CI job to detect models that have been deployed
+// without the corresponding migration.
+//
+// If any difference is found a SchemaError will be returned with all of them.
+// Types are compared in families (any integer column is compatible with any Go
+// integer, etc.) to allow existing tables with different sizes. Only MySQL
+// databases can be verified.
+func (db *Database) VerifySchema(models ...Model) error {
+	if dialectOf(db) != MySQL {
+		return fmt.Errorf("database: schema verification is only supported in MySQL")
+	}
+
+	var serr SchemaError
+	for _, model := range models {
+		mismatches, err := db.verifyModelSchema(model)
+		if err != nil {
+			return err
+		}
+		serr = append(serr, mismatches...)
+	}
+
+	if len(serr) > 0 {
+		return serr
+	}
+	return nil
+}
+
+type tableColumn struct {
+	dataType   string
+	primaryKey bool
+}
+
+func (db *Database) verifyModelSchema(model Model) ([]SchemaMismatch, error) {
+	meta, err := modelMetadataOf(reflect.TypeOf(model))
+	if err != nil {
+		return nil, err
+	}
+	table := model.TableName()
+
+	statement := `
+		SELECT COLUMN_NAME, DATA_TYPE, COLUMN_KEY
+		FROM information_schema.COLUMNS
+		WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = ?
+		ORDER BY ORDINAL_POSITION
+	`
+	rows, err := db.sess.Query(statement, table)
+	if err != nil {
+		return nil, fmt.Errorf("database: cannot query the schema of table %s: %s", table, err)
+	}
+	defer rows.Close()
+
+	var names []string
+	columns := map[string]*tableColumn{}
+	for rows.Next() {
+		var name, dataType, key string
+		if err := rows.Scan(&name, &dataType, &key); err != nil {
+			return nil, err
+		}
+
+		names = append(names, name)
+		columns[name] = &tableColumn{
+			dataType:   strings.ToLower(dataType),
+			primaryKey: key == "PRI",
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return compareSchema(table, meta, names, columns), nil
+}
+
+// compareSchema returns the differences between the metadata of a model and the
+// columns of its table. Names contains the columns in the order of the table.
+// Column names are compared case insensitively like MySQL does.
+func compareSchema(table string, meta *modelMetadata, names []string, columns map[string]*tableColumn) []SchemaMismatch {
+	if len(names) == 0 {
+		return []SchemaMismatch{{Table: table, Problem: "missing table"}}
+	}
+
+	folded := map[string]*tableColumn{}
+	for name, col := range columns {
+		folded[strings.ToLower(name)] = col
+	}
+
+	var mismatches []SchemaMismatch
+	known := map[string]bool{}
+	for _, field := range meta.fields {
+		name := strings.Trim(field.name, "`")
+		known[strings.ToLower(name)] = true
+
+		col, ok := folded[strings.ToLower(name)]
+		if !ok {
+			mismatches = append(mismatches, SchemaMismatch{table, name, "missing column"})
+			continue
+		}
+
+		if _, t, _, err := field.sqlType(); err == nil && !isCompatibleType(t, col.dataType) {
+			mismatches = append(mismatches, SchemaMismatch{table, name, fmt.Sprintf("type %s cannot be stored in a %s column", field.typ, col.dataType)})
+		}
+
+		if field.pk && !col.primaryKey {
+			mismatches = append(mismatches, SchemaMismatch{table, name, "missing primary key"})
+		}
+		if !field.pk && col.primaryKey {
+			mismatches = append(mismatches, SchemaMismatch{table, name, "column is a primary key of the table but not of the model"})
+		}
+	}
+
+	for _, name := range names {
+		if !known[strings.ToLower(name)] {
+			mismatches = append(mismatches, SchemaMismatch{table, name, "extra column"})
+		}
+	}
+
+	return mismatches
+}
+
+func isCompatibleType(t reflect.Type, dataType string) bool {
+	kind := t.Kind()
+	switch kind {
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		kind = reflect.Int
+
+	case reflect.Float32:
+		kind = reflect.Float64
+	}
+
+	for _, compatible := range compatibleTypes[kind] {
+		if compatible == dataType {
+			return true
+		}
+	}
+
+	return false
+}
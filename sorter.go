@@ -1,7 +1,130 @@
 package database
 
+import (
+	"fmt"
+	"strings"
+)
+
 // Sorter should be implemented by any generic SQL order we can apply to collections.
 type Sorter interface {
 	// SQL returns the portion of the code that will be merged to the query.
 	SQL() string
 }
+
+// ValuesSorter can be implemented by sorters that need placeholders with "?"
+// in their SQL to receive runtime values. The values are sent to the database
+// after the ones of the filters, in the same order the sorters were applied.
+// Sorters that only implement Sorter keep working without placeholders. Any
+// Condition is a valid ValuesSorter too, to sort by the result of the comparison.
+type ValuesSorter interface {
+	Sorter
+
+	// Values returns the list of placeholders values we should fill.
+	Values() []interface{}
+}
+
+type sqlSorter struct {
+	sql    string
+	values []interface{}
+}
+
+func (sorter *sqlSorter) SQL() string {
+	return sorter.sql
+}
+
+func (sorter *sqlSorter) Values() []interface{} {
+	return sorter.values
+}
+
+// orderColumn parses a column with an optional "-" prefix for descendent order
+// and returns it escaped along with the direction.
+func orderColumn(column string) (string, string) {
+	direction := "ASC"
+	if strings.HasPrefix(column, "-") {
+		column = column[1:]
+		direction = "DESC"
+	}
+	checkColumn(column)
+
+	return quoteColumn(column), direction
+}
+
+// OrderField sorts the rows in the explicit order of the values using the
+// position of the column value in the list. Rows with values outside the list
+// will be sorted first.
+//
+//	OrderField("status", "pending", "confirmed", "cancelled")
+func OrderField(column string, values ...interface{}) Sorter {
+	if len(values) == 0 {
+		panic("OrderField requires at least one value")
+	}
+	column, _ = orderColumn(column)
+
+	placeholders := make([]string, len(values))
+	for i := range values {
+		placeholders[i] = "?"
+	}
+
+	return &sqlSorter{
+		sql:    fmt.Sprintf("FIELD(%s, %s)", column, strings.Join(placeholders, ", ")),
+		values: values,
+	}
+}
+
+// OrderRandom sorts the rows randomly.
+func OrderRandom() Sorter {
+	return &sqlSorter{sql: "RAND()"}
+}
+
+// OrderRandomSeed sorts the rows randomly, but repeating the same order when the
+// same seed is used. It is useful to paginate random results.
+func OrderRandomSeed(seed int64) Sorter {
+	return &sqlSorter{
+		sql:    "RAND(?)",
+		values: []interface{}{seed},
+	}
+}
+
+// OrderNullsFirst sorts the column putting NULL values before the rest of them
+// independently of the direction. Pass "column" for ascendent order or "-column"
+// for descendent order.
+func OrderNullsFirst(column string) Sorter {
+	column, direction := orderColumn(column)
+	return &sqlSorter{sql: fmt.Sprintf("%s IS NULL DESC, %s %s", column, column, direction)}
+}
+
+// OrderNullsLast sorts the column putting NULL values after the rest of them
+// independently of the direction. Pass "column" for ascendent order or "-column"
+// for descendent order.
+func OrderNullsLast(column string) Sorter {
+	column, direction := orderColumn(column)
+	return &sqlSorter{sql: fmt.Sprintf("%s IS NULL ASC, %s %s", column, column, direction)}
+}
+
+// OrderExpr sorts the rows by a custom SQL expression. It can have placeholders
+// with "?" to fill them with the values. Add ASC or DESC to the expression if needed.
+//
+//	OrderExpr("ABS(price - ?)", 100)
+func OrderExpr(sql string, values ...interface{}) Sorter {
+	if n := strings.Count(sql, "?"); n != len(values) {
+		panic(fmt.Sprintf("OrderExpr has %d placeholders and %d values: %s", n, len(values), sql))
+	}
+
+	return &sqlSorter{
+		sql:    sql,
+		values: values,
+	}
+}
+
+// OrderCollate sorts the column using a specific collation instead of the one of
+// the column. For example you can use utf8mb4_general_ci to sort case-insensitive
+// a column that is stored with utf8mb4_bin. Pass "column" for ascendent order or
+// "-column" for descendent order.
+func OrderCollate(column, collation string) Sorter {
+	if !collationRe.MatchString(collation) {
+		panic(fmt.Sprintf("invalid collation: %s", collation))
+	}
+	column, direction := orderColumn(column)
+
+	return &sqlSorter{sql: fmt.Sprintf("%s COLLATE %s %s", column, collation, direction)}
+}
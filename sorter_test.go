@@ -0,0 +1,94 @@
+package database
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestOrderField(t *testing.T) {
+	sorter := OrderField("status", "pending", "confirmed")
+	require.Equal(t, "FIELD(`status`, ?, ?)", sorter.SQL())
+	require.Equal(t, []interface{}{"pending", "confirmed"}, sorter.(ValuesSorter).Values())
+}
+
+func TestOrderFieldEmpty(t *testing.T) {
+	require.Panics(t, func() { OrderField("status") })
+}
+
+func TestOrderRandom(t *testing.T) {
+	require.Equal(t, "RAND()", OrderRandom().SQL())
+
+	sorter := OrderRandomSeed(42)
+	require.Equal(t, "RAND(?)", sorter.SQL())
+	require.Equal(t, []interface{}{int64(42)}, sorter.(ValuesSorter).Values())
+}
+
+func TestOrderNulls(t *testing.T) {
+	require.Equal(t, "`foo` IS NULL DESC, `foo` ASC", OrderNullsFirst("foo").SQL())
+	require.Equal(t, "`foo` IS NULL DESC, `foo` DESC", OrderNullsFirst("-foo").SQL())
+	require.Equal(t, "`foo` IS NULL ASC, `foo` ASC", OrderNullsLast("foo").SQL())
+	require.Equal(t, "`c`.`foo` IS NULL ASC, `c`.`foo` DESC", OrderNullsLast("-c.foo").SQL())
+}
+
+func TestOrderExpr(t *testing.T) {
+	sorter := OrderExpr("ABS(price - ?)", 100)
+	require.Equal(t, "ABS(price - ?)", sorter.SQL())
+	require.Equal(t, []interface{}{100}, sorter.(ValuesSorter).Values())
+
+	require.Panics(t, func() { OrderExpr("ABS(price - ?)") })
+}
+
+func TestOrderCollate(t *testing.T) {
+	require.Equal(t, "`name` COLLATE utf8mb4_general_ci ASC", OrderCollate("name", "utf8mb4_general_ci").SQL())
+	require.Equal(t, "`name` COLLATE utf8mb4_general_ci DESC", OrderCollate("-name", "utf8mb4_general_ci").SQL())
+
+	require.Panics(t, func() { OrderCollate("name", "utf8mb4; DROP TABLE foo") })
+	require.Panics(t, func() { OrderCollate("name)", "utf8mb4_general_ci") })
+}
+
+type testingLegacySorter struct{}
+
+func (sorter *testingLegacySorter) SQL() string {
+	return "legacy DESC"
+}
+
+func TestSelectSQLOrderValues(t *testing.T) {
+	children := newCollection(new(Database), new(testingRelChild)).Alias("c").Filter("c.foo", "join")
+	c := newCollection(new(Database), new(testingRelParent)).
+		Alias("p").
+		Join(children, "c.parent = p.id").
+		Filter("p.id >", 3).
+		OrderSorter(OrderField("p.id", 5, 4)).
+		OrderSorter(new(testingLegacySorter)).
+		OrderSorter(OrderExpr("ABS(p.id - ?)", 7)).
+		Limit(2)
+
+	b := &sqlBuilder{
+		table:      c.model.TableName(),
+		props:      c.props,
+		conditions: c.conditions,
+		orders:     c.orders,
+		limit:      c.limit,
+		alias:      c.alias,
+		joins:      c.sqlJoins(),
+	}
+	sql, values := b.SelectSQLCols("p.id")
+	require.Equal(t, "SELECT p.id FROM testing_relparent AS p JOIN testing_relchild AS c ON c.parent = p.id AND c.foo = ? WHERE p.id > ? ORDER BY FIELD(`p`.`id`, ?, ?), legacy DESC, ABS(p.id - ?) LIMIT 0,2", sql)
+	require.Equal(t, []interface{}{"join", 3, 5, 4, 7}, values)
+}
+
+func TestSubqueryOrderValues(t *testing.T) {
+	sub := newCollection(new(Database), new(testingRelChild)).Filter("foo", "bar").OrderSorter(OrderField("foo", "a", "b"))
+	cond := FilterIn("id", sub, "parent")
+
+	require.Equal(t, "id IN (SELECT parent FROM testing_relchild WHERE foo = ? ORDER BY FIELD(`foo`, ?, ?))", cond.SQL())
+	require.Equal(t, []interface{}{"bar", "a", "b"}, cond.Values())
+}
+
+func TestConditionAsSorter(t *testing.T) {
+	c := newCollection(new(Database), new(testingModel)).OrderSorter(Filter("name", "foo"))
+	sql, values := (&sqlBuilder{table: "testing", orders: c.orders}).SelectSQLCols("code")
+	require.Equal(t, "SELECT code FROM testing ORDER BY name = ?", sql)
+	require.Equal(t, []interface{}{"foo"}, values)
+}
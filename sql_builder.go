@@ -6,12 +6,23 @@ import (
 )
 
 type sqlBuilder struct {
+	dialect       Dialect
 	table         string
 	props         []*Property
-	orders        []string
+	orders        []Sorter
 	conditions    []Condition
 	limit, offset int64
 	alias         string
+	joins         []*sqlJoin
+}
+
+type sqlJoin struct {
+	// Type of join: "JOIN" or "LEFT JOIN".
+	kind string
+
+	table, alias string
+	on           string
+	conditions   []Condition
 }
 
 func (b *sqlBuilder) cols() []string {
@@ -23,31 +34,81 @@ func (b *sqlBuilder) cols() []string {
 	return cols
 }
 
+// selectCols returns the columns of the select, qualified with the name of the
+// table when there are joins to avoid ambiguous names.
+func (b *sqlBuilder) selectCols() []string {
+	if len(b.joins) == 0 {
+		return b.cols()
+	}
+
+	return qualifyCols(b.qualifier(), b.cols())
+}
+
+// qualifier returns the name that refers to the main table of the query.
+func (b *sqlBuilder) qualifier() string {
+	if b.alias != "" {
+		return b.alias
+	}
+
+	return b.table
+}
+
+func qualifyCols(qualifier string, cols []string) []string {
+	qualified := make([]string, len(cols))
+	for i, col := range cols {
+		qualified[i] = fmt.Sprintf("%s.%s", qualifier, col)
+	}
+
+	return qualified
+}
+
 func (b *sqlBuilder) SelectSQL() (string, []interface{}) {
-	return b.SelectSQLCols(b.cols()...)
+	return b.SelectSQLCols(b.selectCols()...)
 }
 
 func (b *sqlBuilder) SelectSQLCols(cols ...string) (string, []interface{}) {
-	var conds []string
 	var values []interface{}
-	for _, cond := range b.conditions {
-		conds = append(conds, cond.SQL())
-		values = append(values, cond.Values()...)
-	}
 
 	sql := fmt.Sprintf(`SELECT %s FROM %s`, strings.Join(cols, ", "), b.table)
 	if b.alias != "" {
 		sql = fmt.Sprintf("%s AS %s", sql, b.alias)
 	}
 
+	for _, join := range b.joins {
+		sql = fmt.Sprintf("%s %s %s", sql, join.kind, join.table)
+		if join.alias != "" {
+			sql = fmt.Sprintf("%s AS %s", sql, join.alias)
+		}
+
+		on := []string{join.on}
+		for _, cond := range join.conditions {
+			on = append(on, cond.SQL())
+			values = append(values, cond.Values()...)
+		}
+		sql = fmt.Sprintf("%s ON %s", sql, strings.Join(on, " AND "))
+	}
+
+	var conds []string
+	for _, cond := range b.conditions {
+		conds = append(conds, cond.SQL())
+		values = append(values, cond.Values()...)
+	}
+
 	if len(conds) > 0 {
 		sql = fmt.Sprintf("%s WHERE %s", sql, strings.Join(conds, " AND "))
 	}
 	if len(b.orders) > 0 {
-		sql = fmt.Sprintf("%s ORDER BY %s", sql, strings.Join(b.orders, ", "))
+		orders := make([]string, len(b.orders))
+		for i, sorter := range b.orders {
+			orders[i] = sorter.SQL()
+			if vs, ok := sorter.(ValuesSorter); ok {
+				values = append(values, vs.Values()...)
+			}
+		}
+		sql = fmt.Sprintf("%s ORDER BY %s", sql, strings.Join(orders, ", "))
 	}
 	if b.limit > 0 {
-		sql = fmt.Sprintf("%s LIMIT %d,%d", sql, b.offset, b.limit)
+		sql = fmt.Sprintf("%s %s", sql, b.sqlDialect().Limit(b.offset, b.limit))
 	}
 
 	return sql, values
@@ -100,10 +161,15 @@ func (b *sqlBuilder) DeleteSQL() (string, []interface{}) {
 	return sql, values
 }
 
-func (b *sqlBuilder) TruncateSQL() string {
-	return fmt.Sprintf(`DELETE FROM %s`, b.table)
+func (b *sqlBuilder) TruncateSQL() []string {
+	return b.sqlDialect().TruncateSQL(b.table)
 }
 
-func (b *sqlBuilder) ResetAutoIncrementSQL() string {
-	return fmt.Sprintf(`ALTER TABLE %s AUTO_INCREMENT = 1`, b.table)
+// sqlDialect returns the dialect of the builder, or MySQL if none was selected.
+func (b *sqlBuilder) sqlDialect() Dialect {
+	if b.dialect == nil {
+		return MySQL
+	}
+
+	return b.dialect
 }
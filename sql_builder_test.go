@@ -0,0 +1,33 @@
+package database
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSelectSQLJoins(t *testing.T) {
+	b := &sqlBuilder{
+		table: "testing_relparent",
+		alias: "p",
+		props: []*Property{
+			{Name: "`id`"},
+			{Name: "`revision`"},
+		},
+		conditions: []Condition{Filter("p.id >", 3)},
+		orders:     []Sorter{&sqlSorter{sql: "`c`.`foo` DESC"}},
+		joins: []*sqlJoin{
+			{
+				kind:       "LEFT JOIN",
+				table:      "testing_relchild",
+				alias:      "c",
+				on:         "c.parent = p.id",
+				conditions: []Condition{Filter("c.foo", "bar")},
+			},
+		},
+	}
+
+	sql, values := b.SelectSQL()
+	require.Equal(t, "SELECT p.`id`, p.`revision` FROM testing_relparent AS p LEFT JOIN testing_relchild AS c ON c.parent = p.id AND c.foo = ? WHERE p.id > ? ORDER BY `c`.`foo` DESC", sql)
+	require.Equal(t, []interface{}{"bar", 3}, values)
+}
@@ -0,0 +1,233 @@
+package database
+
+import (
+	"context"
+	"iter"
+	"reflect"
+)
+
+// TypedCollection is a Collection of a concrete model type. The results of the
+// read methods are returned with their type and the compiler checks the models
+// passed to the write methods:
+//
+//	hotels := database.NewTypedCollection[*Hotel](db)
+//	list, err := hotels.Filter("active", true).Order("name").GetAll()
+//
+// It shares the filters, orders and the rest of the configuration with the
+// underlying Collection, that can be obtained with the Collection method.
+type TypedCollection[T Model] struct {
+	c *Collection
+}
+
+// NewTypedCollection returns a new typed collection of the model T, that should
+// be a pointer to a model struct.
+func NewTypedCollection[T Model](db *Database) *TypedCollection[T] {
+	return &TypedCollection[T]{c: db.Collection(newModel[T]())}
+}
+
+// newModel returns a new empty instance of the model T.
+func newModel[T Model]() T {
+	return reflect.New(reflect.TypeOf((*T)(nil)).Elem().Elem()).Interface().(T)
+}
+
+// Collection returns the underlying untyped collection. Use it to join the typed
+// collection with other ones or as a subquery.
+func (tc *TypedCollection[T]) Collection() *Collection {
+	return tc.c
+}
+
+// Err returns the first error building the collection. See Collection.Err.
+func (tc *TypedCollection[T]) Err() error {
+	return tc.c.Err()
+}
+
+// Clone returns a new collection with the same filters and configuration of
+// the original one.
+func (tc *TypedCollection[T]) Clone() *TypedCollection[T] {
+	return &TypedCollection[T]{c: tc.c.Clone()}
+}
+
+// Alias changes the name of the table in the SQL query. See Collection.Alias.
+func (tc *TypedCollection[T]) Alias(alias string) *TypedCollection[T] {
+	tc.c.Alias(alias)
+	return tc
+}
+
+// Filter applies a new simple filter to the collection. See Collection.Filter.
+func (tc *TypedCollection[T]) Filter(sql string, value interface{}) *TypedCollection[T] {
+	tc.c.Filter(sql, value)
+	return tc
+}
+
+// FilterIsNil applies a new NULL filter to the collection. See Collection.FilterIsNil.
+func (tc *TypedCollection[T]) FilterIsNil(column string) *TypedCollection[T] {
+	tc.c.FilterIsNil(column)
+	return tc
+}
+
+// FilterIsNotNil applies a new NOT NULL filter to the collection. See Collection.FilterIsNotNil.
+func (tc *TypedCollection[T]) FilterIsNotNil(column string) *TypedCollection[T] {
+	tc.c.FilterIsNotNil(column)
+	return tc
+}
+
+// FilterExpr applies a new filter with named parameters to the collection. See Collection.FilterExpr.
+func (tc *TypedCollection[T]) FilterExpr(sql string, params Named) *TypedCollection[T] {
+	tc.c.FilterExpr(sql, params)
+	return tc
+}
+
+// FilterCond applies a generic condition to the collection. See Collection.FilterCond.
+func (tc *TypedCollection[T]) FilterCond(condition Condition) *TypedCollection[T] {
+	tc.c.FilterCond(condition)
+	return tc
+}
+
+// Offset moves the initial position of the query. See Collection.Offset.
+func (tc *TypedCollection[T]) Offset(offset int64) *TypedCollection[T] {
+	tc.c.Offset(offset)
+	return tc
+}
+
+// Limit adds a maximum number of results to the query. See Collection.Limit.
+func (tc *TypedCollection[T]) Limit(limit int64) *TypedCollection[T] {
+	tc.c.Limit(limit)
+	return tc
+}
+
+// Order the collection of items. See Collection.Order.
+func (tc *TypedCollection[T]) Order(column string) *TypedCollection[T] {
+	tc.c.Order(column)
+	return tc
+}
+
+// OrderSorter sorts the collection of items. See Collection.OrderSorter.
+func (tc *TypedCollection[T]) OrderSorter(sorter Sorter) *TypedCollection[T] {
+	tc.c.OrderSorter(sorter)
+	return tc
+}
+
+// Preload loads the models of a relation after the main query. See Collection.Preload.
+func (tc *TypedCollection[T]) Preload(relation string) *TypedCollection[T] {
+	tc.c.Preload(relation)
+	return tc
+}
+
+// Join adds another collection to the query with an INNER JOIN. See Collection.Join.
+func (tc *TypedCollection[T]) Join(other *Collection, on string) *TypedCollection[T] {
+	tc.c.Join(other, on)
+	return tc
+}
+
+// LeftJoin adds another collection to the query with a LEFT JOIN. See Collection.Join.
+func (tc *TypedCollection[T]) LeftJoin(other *Collection, on string) *TypedCollection[T] {
+	tc.c.LeftJoin(other, on)
+	return tc
+}
+
+// Get retrieves the model matching the collection filters and the model primary key.
+// See Collection.Get.
+func (tc *TypedCollection[T]) Get(instance T) error {
+	return tc.c.Get(instance)
+}
+
+// Put stores a new item of the collection. See Collection.Put.
+func (tc *TypedCollection[T]) Put(instance T) error {
+	return tc.c.Put(instance)
+}
+
+// Delete removes a model from a collection. See Collection.Delete.
+func (tc *TypedCollection[T]) Delete(instance T) error {
+	return tc.c.Delete(instance)
+}
+
+// DeleteMulti removes the rows with the keys from the collection. See Collection.DeleteMulti.
+func (tc *TypedCollection[T]) DeleteMulti(keys interface{}) error {
+	return tc.c.DeleteMulti(keys)
+}
+
+// GetAll returns every model of the collection.
+func (tc *TypedCollection[T]) GetAll() ([]T, error) {
+	models := []T{}
+	if err := tc.c.GetAll(&models); err != nil {
+		return nil, err
+	}
+
+	return models, nil
+}
+
+// GetMulti returns the models of the keys in the same order. If any of them is
+// not found it returns a MultiError and a nil model in its position, see
+// Collection.GetMulti for details.
+func (tc *TypedCollection[T]) GetMulti(keys interface{}) ([]T, error) {
+	var models []T
+	err := tc.c.GetMulti(keys, &models)
+	return models, err
+}
+
+// First returns the first model of the collection, or ErrNoSuchEntity if it is empty.
+func (tc *TypedCollection[T]) First() (T, error) {
+	instance := newModel[T]()
+	if err := tc.c.First(instance); err != nil {
+		var zero T
+		return zero, err
+	}
+
+	return instance, nil
+}
+
+// Count queries the number of rows that the collection matches.
+func (tc *TypedCollection[T]) Count() (int64, error) {
+	return tc.c.Count()
+}
+
+// Iterator returns a new iterator that can be used to extract models one by one
+// in a loop. You should close the iterator after you are done with it.
+func (tc *TypedCollection[T]) Iterator() (*TypedIterator[T], error) {
+	it, err := tc.c.Iterator()
+	if err != nil {
+		return nil, err
+	}
+
+	return &TypedIterator[T]{it: it}, nil
+}
+
+// ForEachBatch walks the collection in primary key order reading batches of
+// models. See Collection.ForEachBatch.
+func (tc *TypedCollection[T]) ForEachBatch(size int64, fn func(batch []T) error, opts ...BatchOption) error {
+	return forEachBatch[T](tc.c, size, fn, opts)
+}
+
+// TypedIterator reads the models of a typed collection one by one.
+type TypedIterator[T Model] struct {
+	it *Iterator
+}
+
+// Close finishes the iteration. Do not use the iterator after closing it.
+func (it *TypedIterator[T]) Close() {
+	it.it.Close()
+}
+
+// Next returns the next model of the list. When the iterator reaches the end of
+// the collection it returns ErrDone.
+func (it *TypedIterator[T]) Next() (T, error) {
+	instance := newModel[T]()
+	if err := it.it.Next(instance); err != nil {
+		var zero T
+		return zero, err
+	}
+
+	return instance, nil
+}
+
+// All returns an iterator over the models of the collection to use in a for range
+// loop. See Collection.All.
+func (tc *TypedCollection[T]) All() iter.Seq2[T, error] {
+	return allModels[T](tc.c)
+}
+
+// Stream reads the models of the collection in the background and sends them
+// through a channel. See Collection.Stream.
+func (tc *TypedCollection[T]) Stream(ctx context.Context, buffer int) (<-chan T, <-chan error) {
+	return streamModels[T](ctx, tc.c, buffer)
+}
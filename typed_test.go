@@ -0,0 +1,62 @@
+package database
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTypedCollectionShareConfig(t *testing.T) {
+	tc := NewTypedCollection[*testingModel](new(Database)).Filter("Name", "foo").Order("-code").Limit(3)
+
+	c := tc.Collection()
+	require.IsType(t, new(testingModel), c.model)
+	require.Len(t, c.conditions, 1)
+	require.Len(t, c.orders, 1)
+	require.EqualValues(t, 3, c.limit)
+
+	clone := tc.Clone().Filter("code", "bar")
+	require.Len(t, clone.Collection().conditions, 2)
+	require.Len(t, c.conditions, 1)
+}
+
+func TestTypedCollection(t *testing.T) {
+	initDatabase(t)
+	defer closeDatabase()
+
+	tc := NewTypedCollection[*testingModel](testDB)
+	require.Nil(t, tc.Put(&testingModel{Code: "foo", Name: "foo name"}))
+	require.Nil(t, tc.Put(&testingModel{Code: "bar", Name: "bar name"}))
+
+	models, err := tc.Clone().Order("code").GetAll()
+	require.Nil(t, err)
+	require.Len(t, models, 2)
+	require.Equal(t, "bar", models[0].Code)
+	require.Equal(t, "foo", models[1].Code)
+
+	first, err := tc.Clone().Filter("code", "foo").First()
+	require.Nil(t, err)
+	require.Equal(t, "foo name", first.Name)
+
+	_, err = tc.Clone().Filter("code", "baz").First()
+	require.Equal(t, ErrNoSuchEntity, err)
+
+	models, err = tc.GetMulti([]string{"foo", "baz"})
+	require.IsType(t, MultiError{}, err)
+	require.Len(t, models, 2)
+	require.Equal(t, "foo name", models[0].Name)
+	require.Nil(t, models[1])
+
+	it, err := tc.Clone().Order("-code").Iterator()
+	require.Nil(t, err)
+	defer it.Close()
+
+	model, err := it.Next()
+	require.Nil(t, err)
+	require.Equal(t, "foo", model.Code)
+	model, err = it.Next()
+	require.Nil(t, err)
+	require.Equal(t, "bar", model.Code)
+	_, err = it.Next()
+	require.Equal(t, ErrDone, err)
+}